@@ -1,28 +1,183 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/JhonX2011/GOWebApplication/api/web"
+	"github.com/JhonX2011/GOWebApplication/database/mysqlconnect"
 	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"golang.org/x/net/netutil"
 )
 
 const (
 	_defaultWebApplicationPort = "8080"
 	_defaultNetworkProtocol    = "tcp"
+	_defaultShutdownTimeout    = 30 * time.Second
 )
 
 type Application struct {
 	*web.Router
 	Logger logger.Logger
 
-	address string
+	address  string
+	listener net.Listener
+	srv      *http.Server
+
+	// admin is the optional internal server configured with WithAdminServer.
+	admin *adminServer
+
+	// draining is set as soon as Shutdown begins, making /ping respond 503 Service Unavailable.
+	draining    atomic.Bool
+	drainPeriod time.Duration
+
+	// connections are the database connections associated with WithConnections, reported in the startup summary.
+	connections    mysqlconnect.Connections
+	startupSummary bool
+
+	mu            sync.Mutex
+	shutdownHooks []shutdownHook
+	reloadHooks   []func() error
+}
+
+// Option configures the optional behavior of the Application created by NewWebApplication.
+type Option func(*options)
+
+type options struct {
+	maxConnections int
+	adminAddress   string
+	adminConfigure func(*web.Router)
+	h2c            bool
+	drainPeriod    time.Duration
+	baseContext    func(net.Listener) context.Context
+	connContext    func(ctx context.Context, c net.Conn) context.Context
+	connections    mysqlconnect.Connections
+	noSummary      bool
+	middlewares    []web.Middleware
+	serverHeader   *string
+}
+
+// adminServer is an internal HTTP server that runs alongside the main one on a separate address.
+type adminServer struct {
+	address  string
+	listener net.Listener
+	srv      *http.Server
+}
+
+// WithMaxConnections limits the number of connections the server accepts simultaneously to n.
+// Connections beyond the limit are not rejected but queued until an accepted connection is closed.
+func WithMaxConnections(n int) Option {
+	return func(o *options) {
+		o.maxConnections = n
+	}
+}
+
+// WithAdminServer runs a second HTTP server on addr with its own router, so internal endpoints such as
+// health checks, metrics or pprof are not exposed on the public port. configure registers the routes of
+// the admin router. The admin server is started by Run and stopped by Shutdown along with the main server.
+func WithAdminServer(addr string, configure func(*web.Router)) Option {
+	return func(o *options) {
+		o.adminAddress = addr
+		o.adminConfigure = configure
+	}
+}
+
+// WithH2C makes the server accept HTTP/2 over cleartext TCP (h2c), for example behind a load balancer
+// that speaks h2c to its backends. HTTP/1.1 requests keep being served as usual.
+func WithH2C() Option {
+	return func(o *options) {
+		o.h2c = true
+	}
+}
+
+// WithDrainPeriod makes Shutdown keep serving requests for d after /ping starts responding
+// 503 Service Unavailable, so the load balancer has time to notice it and stop routing traffic to the
+// instance before the server stops accepting connections. The period is cut short when the shutdown
+// context is done. Without it, /ping flips to 503 only for the requests already in flight.
+func WithDrainPeriod(d time.Duration) Option {
+	return func(o *options) {
+		o.drainPeriod = d
+	}
+}
+
+// WithBaseContext sets the function that returns the base context of every request, for example to seed
+// request independent values such as the service name or shared clients, which handlers and the database
+// layer can then read from the request context. It is used as the BaseContext of both the main and the
+// admin servers.
+func WithBaseContext(fn func(net.Listener) context.Context) Option {
+	return func(o *options) {
+		o.baseContext = fn
+	}
+}
+
+// WithConnContext sets the function that derives the context of every new connection from the base context,
+// for example to store values about the client connection. Every request on the connection inherits it.
+// It is used as the ConnContext of both the main and the admin servers.
+func WithConnContext(fn func(ctx context.Context, c net.Conn) context.Context) Option {
+	return func(o *options) {
+		o.connContext = fn
+	}
+}
+
+// WithConnections associates the database connections of the application, so the startup summary
+// logged by Run includes the number of connections and the driver in use.
+func WithConnections(c mysqlconnect.Connections) Option {
+	return func(o *options) {
+		o.connections = c
+	}
+}
+
+// WithMiddleware installs mw on the Router of the Application, after the request logger and deferred close
+// middlewares and before any route is added, so they also wrap the default routes such as /ping.
+// It can be passed more than once, in which case the middlewares run in the order they were passed.
+// For example, WithMiddleware(web.Recover()) recovers from the panics of the handlers, see web.Recover.
+func WithMiddleware(mw ...web.Middleware) Option {
+	return func(o *options) {
+		o.middlewares = append(o.middlewares, mw...)
+	}
+}
+
+// WithServerHeader sets the Server header of all the responses of the Application to value, overriding
+// the one set by the handlers, or removes it when value is empty, see web.ServerHeader.
+// The responses of the admin server are not affected.
+func WithServerHeader(value string) Option {
+	return func(o *options) {
+		o.serverHeader = &value
+	}
+}
+
+// WithoutStartupSummary suppresses the single line summarizing the application that Run logs on startup.
+func WithoutStartupSummary() Option {
+	return func(o *options) {
+		o.noSummary = true
+	}
 }
 
-func NewWebApplication() (*Application, error) {
+// shutdownHook is a named function executed while the application is shutting down.
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func NewWebApplication(opts ...Option) (*Application, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	l := logger.NewLogger(logger.DefaultOSExit)
 
 	port := os.Getenv("PORT")
@@ -37,36 +192,291 @@ func NewWebApplication() (*Application, error) {
 		l.Fatalf("The provided port [%s] is not available: %v", address, err)
 		return nil, err
 	}
+
+	// Use the address resolved by the listener, which includes the port assigned by the OS when PORT is 0.
+	address = listener.Addr().String()
 	l.Info("Running application | address", address)
-	defer listener.Close()
 
-	return &Application{
-		Router:  web.New(),
-		Logger:  l,
-		address: address,
+	if o.maxConnections > 0 {
+		listener = netutil.LimitListener(listener, o.maxConnections)
+	}
+
+	router := web.New()
+	router.Use(web.RequestLogger(l), web.DeferredClose())
+	if o.serverHeader != nil {
+		router.Use(web.ServerHeader(*o.serverHeader))
+	}
+	router.Use(o.middlewares...)
+
+	var handler http.Handler = router
+	if o.h2c {
+		handler = h2c.NewHandler(router, &http2.Server{})
+	}
+
+	app := &Application{
+		Router:      router,
+		Logger:      l,
+		address:     address,
+		listener:    listener,
+		srv:         newServer(l, address, handler),
+		drainPeriod: o.drainPeriod,
+
+		connections:    o.connections,
+		startupSummary: !o.noSummary,
+	}
+
+	if o.adminAddress != "" {
+		admin, err := newAdminServer(l, o.adminAddress, o.adminConfigure)
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		app.admin = admin
+	}
+
+	app.srv.BaseContext, app.srv.ConnContext = o.baseContext, o.connContext
+	if app.admin != nil {
+		app.admin.srv.BaseContext, app.admin.srv.ConnContext = o.baseContext, o.connContext
+	}
+
+	return app, nil
+}
+
+// newServer returns the HTTP server of the application, which reports its errors, such as TLS handshake
+// or connection errors, through l.
+func newServer(l logger.Logger, address string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         address,
+		Handler:      handler,
+		ErrorLog:     log.New(l.Writer(logger.LevelError), "", 0),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		IdleTimeout:  30 * time.Second,
+	}
+}
+
+func newAdminServer(l logger.Logger, address string, configure func(*web.Router)) (*adminServer, error) {
+	listener, err := net.Listen(_defaultNetworkProtocol, address)
+	if err != nil {
+		return nil, fmt.Errorf("the provided admin address [%s] is not available: %w", address, err)
+	}
+
+	address = listener.Addr().String()
+	l.Info("Running admin server | address", address)
+
+	router := web.New()
+	router.Use(web.RequestLogger(l), web.DeferredClose())
+	if configure != nil {
+		configure(router)
+	}
+
+	return &adminServer{
+		address:  address,
+		listener: listener,
+		srv:      newServer(l, address, router),
 	}, nil
 }
 
+// Run starts the HTTP server and blocks until it fails or the process receives
+// SIGINT or SIGTERM. In both cases the application is gracefully shut down, and the error of the
+// failed server, if any, is joined with the one returned by Shutdown.
+// While running, SIGHUP triggers Reload.
 func (a *Application) Run() error {
 	a.defaultRoutes()
 
-	srv := &http.Server{
-		Addr:         a.address,
-		Handler:      a.Router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  30 * time.Second,
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				_ = a.Reload()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if a.startupSummary {
+		a.Logger.Info(a.summary())
 	}
 
-	if err := srv.ListenAndServe(); err != nil {
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- a.srv.Serve(a.listener)
+	}()
+	if a.admin != nil {
+		go func() {
+			serverErr <- a.admin.srv.Serve(a.admin.listener)
+		}()
+	}
+
+	// When a server fails, the application is shut down as well, so the other server is stopped
+	// and the shutdown hooks are run.
+	var serveErr error
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		serveErr = err
+		a.Logger.Errorf("Server failed, shutting down application | address: %s | error: %s", a.address, err)
+	case <-ctx.Done():
+		a.Logger.Info("Shutting down application | address", a.address)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), _defaultShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(serveErr, a.Shutdown(shutdownCtx))
+}
+
+// summary returns a single line summarizing the application: the listen addresses, the database
+// connections and the driver in use, if any, and whether the debug mode is enabled.
+func (a *Application) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Application started | address: %s", a.address)
+	if a.admin != nil {
+		fmt.Fprintf(&b, " | admin address: %s", a.admin.address)
+	}
+	if a.connections != nil {
+		fmt.Fprintf(&b, " | db connections: %d | driver: %s", len(a.connections.List()), mysqlconnect.DriverName())
+	}
+	fmt.Fprintf(&b, " | debug: %t", os.Getenv("MODE_DEBUG") == "true")
+
+	return b.String()
+}
+
+// Address returns the address the application is listening on, including the port assigned by the OS
+// when the PORT environment variable is 0.
+func (a *Application) Address() string {
+	return a.address
+}
+
+// AdminAddress returns the address the admin server is listening on, or an empty string
+// when the application was created without WithAdminServer.
+func (a *Application) AdminAddress() string {
+	if a.admin == nil {
+		return ""
+	}
+	return a.admin.address
+}
+
+// OnReload registers fn to be executed by Reload, which Run triggers when the process receives SIGHUP,
+// for example to re-read a config file and apply the new pool settings of the database connections
+// without restarting. Callbacks run in registration order.
+func (a *Application) OnReload(fn func() error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.reloadHooks = append(a.reloadHooks, fn)
+}
+
+// Reload runs every callback registered with OnReload and logs the result with the application logger.
+// All callbacks are executed even if a previous one fails, and the errors are aggregated into a single returned error.
+func (a *Application) Reload() error {
+	a.mu.Lock()
+	hooks := make([]func() error, len(a.reloadHooks))
+	copy(hooks, a.reloadHooks)
+	a.mu.Unlock()
+
+	var errs []string
+	for _, hook := range hooks {
+		if err := hook(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		err := fmt.Errorf("failed to reload application: %s", strings.Join(errs, ", "))
+		a.Logger.Error(err)
 		return err
 	}
 
+	a.Logger.Info("Application reloaded")
+	return nil
+}
+
+// RegisterShutdownHook registers fn to be executed during Shutdown, after the HTTP server
+// has stopped accepting requests. Hooks run in registration order and each one receives
+// the shutdown context, so they must honor its deadline.
+func (a *Application) RegisterShutdownHook(name string, fn func(ctx context.Context) error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.shutdownHooks = append(a.shutdownHooks, shutdownHook{name: name, fn: fn})
+}
+
+// Draining reports whether Shutdown has begun, in which case /ping responds 503 Service Unavailable.
+func (a *Application) Draining() bool {
+	return a.draining.Load()
+}
+
+// Shutdown gracefully stops the HTTP server, and the admin server if any, and then runs every registered shutdown hook.
+// As soon as it begins, /ping responds 503 Service Unavailable, and the server keeps serving for the period
+// set with WithDrainPeriod, if any, so the load balancer stops routing traffic before the server stops.
+// All hooks are executed even if the server or a previous hook fails, and the errors
+// are aggregated into a single returned error.
+func (a *Application) Shutdown(ctx context.Context) error {
+	a.draining.Store(true)
+	if a.drainPeriod > 0 {
+		a.Logger.Info("Draining application | period", a.drainPeriod)
+
+		timer := time.NewTimer(a.drainPeriod)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	var errs []string
+	if err := a.srv.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Sprintf("server: %s", err))
+	}
+
+	// The listener is already closed if the server was running, but it must be released otherwise.
+	if err := a.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		errs = append(errs, fmt.Sprintf("listener: %s", err))
+	}
+
+	if a.admin != nil {
+		if err := a.admin.srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Sprintf("admin server: %s", err))
+		}
+		if err := a.admin.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, fmt.Sprintf("admin listener: %s", err))
+		}
+	}
+
+	a.mu.Lock()
+	hooks := make([]shutdownHook, len(a.shutdownHooks))
+	copy(hooks, a.shutdownHooks)
+	a.mu.Unlock()
+
+	for _, hook := range hooks {
+		if err := hook.fn(ctx); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", hook.name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to shutdown application: %s", strings.Join(errs, ", "))
+	}
+
 	return nil
 }
 
 func (a *Application) defaultRoutes() {
 	a.Router.Get("/ping", func(w http.ResponseWriter, r *http.Request) error {
+		if a.draining.Load() {
+			return web.EncodeJSON(w, "draining", http.StatusServiceUnavailable)
+		}
 		return web.EncodeJSON(w, "pong", 200)
 	})
 }
@@ -0,0 +1,594 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/api/web"
+	"github.com/JhonX2011/GOWebApplication/database/mysqlconnect"
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func newTestApplication(t *testing.T) *Application {
+	t.Helper()
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication()
+	require.NoError(t, err)
+
+	return app
+}
+
+func TestApplication_ShutdownHooksRunInRegistrationOrder(t *testing.T) {
+	app := newTestApplication(t)
+
+	var calls []string
+	for _, name := range []string{"metrics", "database", "queues"} {
+		app.RegisterShutdownHook(name, func(ctx context.Context) error {
+			require.NoError(t, ctx.Err())
+			calls = append(calls, name)
+			return nil
+		})
+	}
+
+	err := app.Shutdown(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"metrics", "database", "queues"}, calls)
+}
+
+func TestApplication_ShutdownHooksAggregateErrors(t *testing.T) {
+	app := newTestApplication(t)
+
+	var calls int
+	app.RegisterShutdownHook("metrics", func(ctx context.Context) error {
+		calls++
+		return errors.New("flush failed")
+	})
+	app.RegisterShutdownHook("database", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+	app.RegisterShutdownHook("queues", func(ctx context.Context) error {
+		calls++
+		return errors.New("drain failed")
+	})
+
+	err := app.Shutdown(context.Background())
+	require.EqualError(t, err, "failed to shutdown application: metrics: flush failed, queues: drain failed")
+	require.Equal(t, 3, calls)
+}
+
+func TestApplication_Address(t *testing.T) {
+	app := newTestApplication(t)
+
+	host, port, err := net.SplitHostPort(app.Address())
+	require.NoError(t, err)
+	require.NotEqual(t, "0", port)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	res, err := http.Get("http://" + net.JoinHostPort(host, port) + "/ping")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, `"pong"`, string(body))
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_WithMaxConnections(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithMaxConnections(1))
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	// Hold the only available connection with an incomplete request.
+	conn, err := net.Dial("tcp", app.Address())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET /ping HTTP/1.1\r\n"))
+	require.NoError(t, err)
+
+	client := &http.Client{Timeout: 200 * time.Millisecond}
+	_, err = client.Get("http://" + app.Address() + "/ping")
+	require.Error(t, err, "the connection beyond the limit must be queued")
+
+	// Once the held connection is closed the queued connections are served.
+	require.NoError(t, conn.Close())
+
+	client.Timeout = time.Second
+	res, err := client.Get("http://" + app.Address() + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_WithAdminServer(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithAdminServer("127.0.0.1:0", func(r *web.Router) {
+		r.Get("/metrics", func(w http.ResponseWriter, r *http.Request) error {
+			return web.EncodeJSON(w, "metrics", http.StatusOK)
+		})
+	}))
+	require.NoError(t, err)
+	require.NotEmpty(t, app.AdminAddress())
+	require.NotEqual(t, app.Address(), app.AdminAddress())
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	get := func(address, path string) (int, string) {
+		res, err := http.Get("http://" + address + path)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, string(body)
+	}
+
+	status, body := get(app.Address(), "/ping")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `"pong"`, body)
+
+	status, body = get(app.AdminAddress(), "/metrics")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `"metrics"`, body)
+
+	// The admin routes are not exposed on the public port, and vice versa.
+	status, _ = get(app.Address(), "/metrics")
+	require.Equal(t, http.StatusNotFound, status)
+	status, _ = get(app.AdminAddress(), "/ping")
+	require.Equal(t, http.StatusNotFound, status)
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+
+	_, err = net.Dial("tcp", app.AdminAddress())
+	require.Error(t, err, "the admin server must be stopped by Shutdown")
+}
+
+func TestApplication_RunShutsDownWhenAServerFails(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithAdminServer("127.0.0.1:0", nil))
+	require.NoError(t, err)
+
+	var hookCalled bool
+	app.RegisterShutdownHook("cache", func(context.Context) error {
+		hookCalled = true
+		return nil
+	})
+
+	// The admin server fails as soon as it starts, since its listener is closed.
+	require.NoError(t, app.admin.listener.Close())
+
+	err = app.Run()
+	require.ErrorIs(t, err, net.ErrClosed)
+	require.True(t, hookCalled, "the shutdown hooks must run when a server fails")
+
+	_, err = net.Dial("tcp", app.Address())
+	require.Error(t, err, "the other server must be stopped when a server fails")
+}
+
+func TestApplication_WithAdminServerAddressInUse(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	_, err = NewWebApplication(WithAdminServer(busy.Addr().String(), nil))
+	require.ErrorContains(t, err, "the provided admin address ["+busy.Addr().String()+"] is not available")
+}
+
+func TestApplication_WithH2C(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithH2C())
+	require.NoError(t, err)
+	app.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return web.EncodeJSON(w, r.Proto+":"+web.Param(r, "id"), http.StatusOK)
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	h2cClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		client   *http.Client
+		expected string
+	}{
+		{client: h2cClient, expected: `"HTTP/2.0:42"`},
+		{client: http.DefaultClient, expected: `"HTTP/1.1:42"`},
+	} {
+		res, err := tc.client.Get("http://" + app.Address() + "/users/42")
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, tc.expected, string(body))
+	}
+
+	h2cClient.CloseIdleConnections()
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_Reload(t *testing.T) {
+	app := newTestApplication(t)
+
+	var calls []string
+	app.OnReload(func() error {
+		calls = append(calls, "config")
+		return errors.New("invalid config file")
+	})
+	app.OnReload(func() error {
+		calls = append(calls, "pools")
+		return nil
+	})
+
+	err := app.Reload()
+	require.EqualError(t, err, "failed to reload application: invalid config file")
+	require.Equal(t, []string{"config", "pools"}, calls)
+}
+
+func TestApplication_ReloadOnSIGHUP(t *testing.T) {
+	app := newTestApplication(t)
+
+	reloaded := make(chan struct{}, 1)
+	app.OnReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	// Run listens for SIGHUP before serving requests, so the signal is only sent once it is serving.
+	require.Eventually(t, func() bool {
+		res, err := http.Get("http://" + app.Address() + "/ping")
+		if err != nil {
+			return false
+		}
+		_ = res.Body.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Fatal("the reload callback was not executed")
+	}
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_PingWhileDraining(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithDrainPeriod(time.Second))
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	app.Router.Get("/slow", func(w http.ResponseWriter, r *http.Request) error {
+		close(started)
+		<-release
+		return web.EncodeJSON(w, "done", http.StatusOK)
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	ping := func() int {
+		res, err := http.Get("http://" + app.Address() + "/ping")
+		if err != nil {
+			return 0
+		}
+		_ = res.Body.Close()
+		return res.StatusCode
+	}
+	require.Eventually(t, func() bool { return ping() == http.StatusOK }, time.Second, 10*time.Millisecond)
+
+	slowRes := make(chan *http.Response, 1)
+	go func() {
+		res, err := http.Get("http://" + app.Address() + "/slow")
+		require.NoError(t, err)
+		slowRes <- res
+	}()
+	<-started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- app.Shutdown(context.Background())
+	}()
+
+	// /ping flips to 503 as soon as the shutdown begins, while the server keeps serving.
+	require.Eventually(t, func() bool { return ping() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+	require.True(t, app.Draining())
+
+	// The request in flight still completes.
+	close(release)
+	res := <-slowRes
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+
+	require.NoError(t, <-shutdownErr)
+	require.NoError(t, <-runErr)
+}
+
+type serviceKey struct{}
+
+type connKey struct{}
+
+func TestApplication_WithBaseContext(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(
+		WithBaseContext(func(net.Listener) context.Context {
+			return context.WithValue(context.Background(), serviceKey{}, "users-api")
+		}),
+		WithConnContext(func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connKey{}, c.RemoteAddr().String())
+		}),
+	)
+	require.NoError(t, err)
+
+	app.Router.Get("/service", func(w http.ResponseWriter, r *http.Request) error {
+		service, _ := r.Context().Value(serviceKey{}).(string)
+		remoteAddr, _ := r.Context().Value(connKey{}).(string)
+		return web.EncodeJSON(w, map[string]bool{
+			"service": service == "users-api",
+			"conn":    remoteAddr == r.RemoteAddr,
+		}, http.StatusOK)
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	res, err := http.Get("http://" + app.Address() + "/service")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.JSONEq(t, `{"service":true,"conn":true}`, string(body))
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+// fakeConnections are Connections that only implement List.
+type fakeConnections struct {
+	mysqlconnect.Connections
+	dbs []*sql.DB
+}
+
+func (c fakeConnections) List() []*sql.DB {
+	return c.dbs
+}
+
+// syncBuffer is a bytes.Buffer safe to write from the server goroutines while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestApplication_StartupSummary(t *testing.T) {
+	t.Setenv("PORT", "0")
+	t.Setenv("MODE_DEBUG", "true")
+
+	app, err := NewWebApplication(WithConnections(fakeConnections{dbs: make([]*sql.DB, 2)}))
+	require.NoError(t, err)
+	require.Equal(t, "Application started | address: "+app.Address()+" | db connections: 2 | driver: mysql | debug: true",
+		app.summary())
+
+	output := new(syncBuffer)
+	app.Logger = logger.NewLogger(nil, logger.WithOutput(output))
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), "Application started | address: "+app.Address())
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+	require.Equal(t, 1, strings.Count(output.String(), "Application started"))
+}
+
+func TestApplication_WithoutStartupSummary(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithoutStartupSummary())
+	require.NoError(t, err)
+	require.Equal(t, "Application started | address: "+app.Address()+" | debug: false", app.summary())
+
+	output := new(syncBuffer)
+	app.Logger = logger.NewLogger(nil, logger.WithOutput(output))
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	res, err := http.Get("http://" + app.Address() + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+	require.NotContains(t, output.String(), "Application started")
+}
+
+func TestApplication_ServerError(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	app, err := NewWebApplication(WithMiddleware(web.Recover()), WithoutStartupSummary())
+	require.NoError(t, err)
+	app.Router.ErrorHandler(func(ctx context.Context, err error) {})
+	app.Router.Get("/users", func(w http.ResponseWriter, r *http.Request) error {
+		return web.NewError(http.StatusServiceUnavailable, "db down")
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	res, err := http.Get("http://" + app.Address() + "/users")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+
+	// The 5xx errors keep their status code with an error handler that doesn't panic.
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	require.JSONEq(t, `{"code":"service_unavailable","message":"db down"}`, string(body))
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_WithMiddleware(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	var calls []string
+	tag := func(name string) web.Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				w.Header().Set("X-Middleware", name)
+				next(w, r)
+			}
+		}
+	}
+
+	app, err := NewWebApplication(WithMiddleware(tag("first")), WithMiddleware(tag("second")), WithoutStartupSummary())
+	require.NoError(t, err)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- app.Run()
+	}()
+
+	res, err := http.Get("http://" + app.Address() + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "second", res.Header.Get("X-Middleware"))
+	require.Equal(t, []string{"first", "second"}, calls)
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.NoError(t, <-runErr)
+}
+
+func TestApplication_WithServerHeader(t *testing.T) {
+	t.Setenv("PORT", "0")
+
+	testCases := []struct {
+		name     string
+		value    string
+		expected []string
+	}{
+		{name: "custom value", value: "payments", expected: []string{"payments"}},
+		{name: "suppressed", value: "", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			upstream := func(next http.HandlerFunc) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Server", "upstream/1.2")
+					next(w, r)
+				}
+			}
+
+			app, err := NewWebApplication(WithServerHeader(tc.value), WithMiddleware(upstream), WithoutStartupSummary())
+			require.NoError(t, err)
+
+			runErr := make(chan error, 1)
+			go func() {
+				runErr <- app.Run()
+			}()
+
+			res, err := http.Get("http://" + app.Address() + "/ping")
+			require.NoError(t, err)
+			require.NoError(t, res.Body.Close())
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Equal(t, tc.expected, res.Header.Values("Server"))
+
+			require.NoError(t, app.Shutdown(context.Background()))
+			require.NoError(t, <-runErr)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// BindQuery populates the fields of the struct pointed to by v tagged with `query:"name"` from the query
+// parameters of r with the same name, converting them to the type of the field. The supported types are
+// string, bool, the integer and float types, pointers to them, which are only set when the parameter is
+// present, and slices of them, which receive every value of a repeated parameter, e.g. ?id=1&id=2.
+// Fields whose parameter is missing keep their value, so defaults can be set before calling it.
+// When some values can't be converted, it returns a *ValidationError, rendered as 400 Bad Request,
+// with a FieldError per parameter. Any other error means that v or one of its tagged fields is not supported.
+func BindQuery(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid BindQuery target %T: it must be a non-nil pointer to a struct", v)
+	}
+	rv = rv.Elem()
+
+	query := r.URL.Query()
+
+	var fields []FieldError
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		name := sf.Tag.Get("query")
+		if name == "" || name == "-" {
+			continue
+		}
+		if !sf.IsExported() {
+			return fmt.Errorf("invalid BindQuery target %T: field %s is not exported", v, sf.Name)
+		}
+
+		values, ok := query[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		fieldErr, err := bindValues(rv.Field(i), values)
+		if err != nil {
+			return fmt.Errorf("invalid BindQuery target %T: field %s: %w", v, sf.Name, err)
+		}
+		if fieldErr != "" {
+			fields = append(fields, FieldError{Field: name, Message: fieldErr})
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Message: "invalid query parameters", Fields: fields}
+	}
+
+	return nil
+}
+
+// bindValues sets the values of a query parameter to field. It returns the message describing why
+// a value can't be converted, or an error when the type of field is not supported.
+func bindValues(field reflect.Value, values []string) (string, error) {
+	switch field.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if msg, err := bindValue(slice.Index(i), value); msg != "" || err != nil {
+				return msg, err
+			}
+		}
+		field.Set(slice)
+		return "", nil
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if msg, err := bindValue(ptr.Elem(), values[0]); msg != "" || err != nil {
+			return msg, err
+		}
+		field.Set(ptr)
+		return "", nil
+	default:
+		return bindValue(field, values[0])
+	}
+}
+
+// bindValue converts value to the type of field and sets it.
+func bindValue(field reflect.Value, value string) (string, error) {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Sprintf("must be a boolean, got %q", value), nil
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("must be an integer, got %q", value), nil
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("must be a non negative integer, got %q", value), nil
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return fmt.Sprintf("must be a number, got %q", value), nil
+		}
+		field.SetFloat(f)
+	default:
+		return "", fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return "", nil
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type listUsersQuery struct {
+	Name     string   `query:"name"`
+	Page     int      `query:"page"`
+	Limit    uint8    `query:"limit"`
+	Active   bool     `query:"active"`
+	MinScore float64  `query:"min_score"`
+	IDs      []int64  `query:"id"`
+	Tags     []string `query:"tag"`
+	Country  *string  `query:"country"`
+	Ratio    *float32 `query:"ratio"`
+	Ignored  string   `query:"-"`
+	Untagged string
+}
+
+func TestBindQuery(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet,
+		"/users?name=John&page=2&limit=50&active=true&min_score=4.5&id=1&id=2&tag=a&tag=b&country=CO&ratio=0.5&Untagged=x&-=y", nil)
+
+	var q listUsersQuery
+	require.NoError(t, BindQuery(r, &q))
+
+	country, ratio := "CO", float32(0.5)
+	require.Equal(t, listUsersQuery{
+		Name:     "John",
+		Page:     2,
+		Limit:    50,
+		Active:   true,
+		MinScore: 4.5,
+		IDs:      []int64{1, 2},
+		Tags:     []string{"a", "b"},
+		Country:  &country,
+		Ratio:    &ratio,
+	}, q)
+}
+
+func TestBindQuery_MissingOptionalFields(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?name=John", nil)
+
+	q := listUsersQuery{Page: 1, Limit: 20}
+	require.NoError(t, BindQuery(r, &q))
+
+	require.Equal(t, listUsersQuery{Name: "John", Page: 1, Limit: 20}, q)
+	require.Nil(t, q.IDs)
+	require.Nil(t, q.Country)
+}
+
+func TestBindQuery_ConversionErrors(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?page=two&limit=300&active=yes&min_score=high&id=1&id=x&ratio=", nil)
+
+	var q listUsersQuery
+	err := BindQuery(r, &q)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, http.StatusBadRequest, validationErr.StatusCode())
+	require.Equal(t, []FieldError{
+		{Field: "page", Message: `must be an integer, got "two"`},
+		{Field: "limit", Message: `must be a non negative integer, got "300"`},
+		{Field: "active", Message: `must be a boolean, got "yes"`},
+		{Field: "min_score", Message: `must be a number, got "high"`},
+		{Field: "id", Message: `must be an integer, got "x"`},
+		{Field: "ratio", Message: `must be a number, got ""`},
+	}, validationErr.Fields)
+}
+
+func TestBindQuery_InvalidTarget(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users?created=2024-05-12", nil)
+
+	var q listUsersQuery
+	require.EqualError(t, BindQuery(r, q), "invalid BindQuery target web.listUsersQuery: it must be a non-nil pointer to a struct")
+
+	var unsupported struct {
+		Created map[string]string `query:"created"`
+	}
+	require.ErrorContains(t, BindQuery(r, &unsupported), "field Created: unsupported type map[string]string")
+}
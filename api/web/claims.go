@@ -0,0 +1,19 @@
+package web
+
+import (
+	"context"
+)
+
+// ContextWithClaims returns a copy of ctx carrying claims, the claims of the token that authenticated
+// the request, so handlers can retrieve them with ClaimsFromContext. It is meant for authentication
+// middlewares, such as jwtauth.JWTAuth.
+func ContextWithClaims(ctx context.Context, claims map[string]interface{}) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext returns the claims stored by ContextWithClaims. It reports false if the context
+// carries no claims, meaning that the request was not authenticated.
+func ClaimsFromContext(ctx context.Context) (map[string]interface{}, bool) {
+	claims, ok := ctx.Value(claimsKey).(map[string]interface{})
+	return claims, ok
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsFromContext(t *testing.T) {
+	_, ok := ClaimsFromContext(context.Background())
+	require.False(t, ok)
+
+	ctx := ContextWithClaims(context.Background(), map[string]interface{}{"sub": "user-42"})
+	claims, ok := ClaimsFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, map[string]interface{}{"sub": "user-42"}, claims)
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// closers are the io.Closer registered with Defer during a request.
+type closers struct {
+	mu   sync.Mutex
+	list []io.Closer
+}
+
+// Defer registers closer, such as a *sql.Rows or a *sql.Stmt, to be closed by the DeferredClose middleware
+// once the handler of r returns, even if it fails or panics, so that forgetting to close it doesn't leak
+// its database connection. It is safe to call from the goroutines of the handler.
+// When DeferredClose is not installed, closer is not registered and a warning is logged, so closer must
+// be closed by the caller.
+func Defer(r *http.Request, closer io.Closer) {
+	c, ok := r.Context().Value(closersKey).(*closers)
+	if !ok {
+		LoggerFromContext(r.Context()).Warningf("Closer not registered, the DeferredClose middleware is not installed | path: %s",
+			r.URL.Path)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.list = append(c.list, closer)
+}
+
+// DeferredClose returns a Middleware that closes the io.Closer registered with Defer once the handler returns,
+// in the reverse order of registration, like deferred calls. The errors returned by Close are logged.
+// It is installed by default on the Router of the Application created with api.NewWebApplication.
+func DeferredClose() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			c := &closers{}
+			defer c.closeAll(r.Context())
+
+			next(w, r.WithContext(context.WithValue(r.Context(), closersKey, c)))
+		}
+	}
+}
+
+// closeAll closes the registered closers in the reverse order of registration, logging the errors.
+func (c *closers) closeAll(ctx context.Context) {
+	c.mu.Lock()
+	list := c.list
+	c.list = nil
+	c.mu.Unlock()
+
+	for i := len(list) - 1; i >= 0; i-- {
+		if err := list[i].Close(); err != nil {
+			LoggerFromContext(ctx).Warningf("Failed to close deferred closer | error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// closerFunc is an io.Closer calling the function.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func TestDeferredClose(t *testing.T) {
+	var closed []string
+	closer := func(name string) closerFunc {
+		return func() error {
+			closed = append(closed, name)
+			return nil
+		}
+	}
+
+	h := DeferredClose()(func(w http.ResponseWriter, r *http.Request) {
+		Defer(r, closer("rows"))
+		Defer(r, closer("stmt"))
+		require.Empty(t, closed)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, []string{"stmt", "rows"}, closed)
+}
+
+func TestDeferredClose_Panic(t *testing.T) {
+	var closed bool
+	h := wrapMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		Defer(r, closerFunc(func() error {
+			closed = true
+			return nil
+		}))
+		panic("nil map")
+	}, []Middleware{Recover(), DeferredClose()})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.True(t, closed)
+}
+
+func TestDeferredClose_CloseError(t *testing.T) {
+	output := new(bytes.Buffer)
+	l := logger.NewLogger(nil, logger.WithOutput(output), logger.WithFormat(logger.FormatLogfmt))
+
+	h := wrapMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		Defer(r, closerFunc(func() error { return errors.New("bad connection") }))
+	}, []Middleware{RequestLogger(l), DeferredClose()})
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Contains(t, output.String(), ` level=warning `)
+	require.Contains(t, output.String(), ` msg="Failed to close deferred closer | error: bad connection"`)
+}
+
+func TestDefer_WithoutMiddleware(t *testing.T) {
+	output := new(bytes.Buffer)
+	l := logger.NewLogger(nil, logger.WithOutput(output), logger.WithFormat(logger.FormatLogfmt))
+
+	var closed bool
+	h := RequestLogger(l)(func(w http.ResponseWriter, r *http.Request) {
+		Defer(r, closerFunc(func() error {
+			closed = true
+			return nil
+		}))
+	})
+
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	require.False(t, closed)
+	require.Contains(t, output.String(), "DeferredClose middleware is not installed | path: /users")
+}
@@ -2,6 +2,7 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -33,17 +34,99 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
-// NewError creates a new error with the given status code and message.
+// NewError creates a new error with the given status code and message. The message is used as is,
+// so it may contain % characters.
 func NewError(statusCode int, message string) error {
-	return NewErrorf(statusCode, message) //nolint:govet
+	return &Error{
+		Code:    errorCode(statusCode),
+		Message: message,
+		Status:  statusCode,
+	}
 }
 
 // NewErrorf creates a new error with the given status code and the message
 // formatted according to args and format.
 func NewErrorf(status int, format string, args ...interface{}) error {
 	return &Error{
-		Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Code:    errorCode(status),
 		Message: fmt.Sprintf(format, args...),
 		Status:  status,
 	}
 }
+
+// errorCode returns the snake_case status text of status, e.g. not_found for 404.
+func errorCode(status int) string {
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// FieldError describes why the value of a single field is invalid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is an error carrying the field-level failures of a request validation.
+// EncodeError renders the failures under error.details so clients can map them to form fields.
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+// Error returns a string message of the error including every field failure.
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = fmt.Sprintf("%s: %s", f.Field, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Message, strings.Join(fields, ", "))
+}
+
+// StatusCode returns 400 Bad Request.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// errorEnvelope is the JSON body written by EncodeError.
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// EncodeError writes err as a JSON error envelope of the form {"error": {"code": ..., "message": ...}}.
+// The status code is taken from StatusCoder, or 500 if err doesn't implement it. When err is, or wraps,
+// a *ValidationError its field failures are rendered as an array under error.details. Only the messages of
+// *Error and *ValidationError are sent to the client, the other errors get the status text as message.
+func EncodeError(w http.ResponseWriter, err error) error {
+	status := http.StatusInternalServerError
+	if sc, ok := err.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+
+	// The message of the other errors may carry internal details, such as SQL or host names,
+	// so only the status text is sent to the client.
+	body := errorBody{
+		Code:    errorCode(status),
+		Message: http.StatusText(status),
+	}
+
+	var webErr *Error
+	var validationErr *ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		status = validationErr.StatusCode()
+		body.Code = errorCode(status)
+		body.Message = validationErr.Message
+		body.Details = validationErr.Fields
+	case errors.As(err, &webErr):
+		status = webErr.StatusCode()
+		body.Code = webErr.Code
+		body.Message = webErr.Message
+	}
+
+	return EncodeJSON(w, errorEnvelope{Error: body}, status)
+}
@@ -0,0 +1,96 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "validation error with multiple fields",
+			err: &ValidationError{
+				Message: "invalid user",
+				Fields: []FieldError{
+					{Field: "email", Message: "must be a valid email address"},
+					{Field: "age", Message: "must be greater than 18"},
+				},
+			},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":{"code":"bad_request","message":"invalid user","details":[` +
+				`{"field":"email","message":"must be a valid email address"},` +
+				`{"field":"age","message":"must be greater than 18"}]}}`,
+		},
+		{
+			name: "wrapped validation error",
+			err: fmt.Errorf("creating user: %w", &ValidationError{
+				Message: "invalid user",
+				Fields:  []FieldError{{Field: "email", Message: "is required"}},
+			}),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":{"code":"bad_request","message":"invalid user","details":[` +
+				`{"field":"email","message":"is required"}]}}`,
+		},
+		{
+			name:         "web error",
+			err:          NewError(http.StatusNotFound, "user not found"),
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"error":{"code":"not_found","message":"user not found"}}`,
+		},
+		{
+			name:         "web error with a percent sign",
+			err:          NewError(http.StatusBadRequest, "discount must be at most 100%"),
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"error":{"code":"bad_request","message":"discount must be at most 100%"}}`,
+		},
+		{
+			name:         "plain error",
+			err:          errors.New("dial tcp db-master.internal:3306: connection refused"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"error":{"code":"internal_server_error","message":"Internal Server Error"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := EncodeError(w, tc.err)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.JSONEq(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestEncodeError_HidesUnknownErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeError(w, errors.New("Error 1146: Table 'payments.refunds' doesn't exist"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.NotContains(t, w.Body.String(), "payments.refunds")
+}
+
+func TestValidationError_Error(t *testing.T) {
+	err := &ValidationError{
+		Message: "invalid user",
+		Fields: []FieldError{
+			{Field: "email", Message: "is required"},
+			{Field: "age", Message: "must be a number"},
+		},
+	}
+
+	require.EqualError(t, err, "invalid user: email: is required, age: must be a number")
+	require.Equal(t, http.StatusBadRequest, err.StatusCode())
+}
@@ -0,0 +1,123 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// MarshalOmitNull works like json.Marshal but omits the object fields whose value is null, at any depth,
+// without requiring omitempty on every struct field. The order of the fields is preserved, and the null
+// elements of arrays are kept since their position is meaningful. It can be used as JSONMarshal.
+func MarshalOmitNull(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if _, err := writeWithoutNulls(dec, &buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeWithoutNulls writes the next JSON value of dec into buf, omitting the null object fields,
+// and reports whether the value is null.
+func writeWithoutNulls(dec *json.Decoder, buf *bytes.Buffer) (bool, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '{' {
+			return false, writeObjectWithoutNulls(dec, buf)
+		}
+		return false, writeArrayWithoutNulls(dec, buf)
+	case nil:
+		buf.WriteString("null")
+		return true, nil
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprint(buf, t)
+	default:
+		s, err := json.Marshal(t)
+		if err != nil {
+			return false, err
+		}
+		buf.Write(s)
+	}
+
+	return false, nil
+}
+
+func writeObjectWithoutNulls(dec *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('{')
+
+	var value bytes.Buffer
+	first := true
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		value.Reset()
+		isNull, err := writeWithoutNulls(dec, &value)
+		if err != nil {
+			return err
+		}
+		if isNull {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		k, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(value.Bytes())
+	}
+
+	// Consume the closing delimiter.
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+func writeArrayWithoutNulls(dec *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if _, err := writeWithoutNulls(dec, buf); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing delimiter.
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	buf.WriteByte(']')
+
+	return nil
+}
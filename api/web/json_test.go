@@ -0,0 +1,73 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalOmitNull(t *testing.T) {
+	type address struct {
+		Street *string `json:"street"`
+		City   string  `json:"city"`
+	}
+	type user struct {
+		ID        int                    `json:"id"`
+		Name      string                 `json:"name"`
+		Nickname  *string                `json:"nickname"`
+		Address   *address               `json:"address"`
+		Addresses []*address             `json:"addresses"`
+		Metadata  map[string]interface{} `json:"metadata"`
+		Active    bool                   `json:"active"`
+		Balance   float64                `json:"balance"`
+	}
+
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{
+			name:     "null fields",
+			value:    user{ID: 42, Name: "John"},
+			expected: `{"id":42,"name":"John","active":false,"balance":0}`,
+		},
+		{
+			name: "nested null fields and null array elements",
+			value: user{
+				ID:        42,
+				Name:      "<John>",
+				Address:   &address{City: "Bogotá"},
+				Addresses: []*address{nil, {City: "Medellín"}},
+				Metadata:  map[string]interface{}{"a": nil, "b": []interface{}{1.5, nil, true}},
+				Active:    true,
+				Balance:   1e21,
+			},
+			expected: `{"id":42,"name":"\u003cJohn\u003e","address":{"city":"Bogotá"},` +
+				`"addresses":[null,{"city":"Medellín"}],"metadata":{"b":[1.5,null,true]},"active":true,"balance":1e+21}`,
+		},
+		{
+			name:     "null",
+			value:    nil,
+			expected: `null`,
+		},
+		{
+			name:     "empty object",
+			value:    map[string]interface{}{"a": nil},
+			expected: `{}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := MarshalOmitNull(tc.value)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestMarshalOmitNull_Error(t *testing.T) {
+	_, err := MarshalOmitNull(func() {})
+	require.EqualError(t, err, "json: unsupported type: func()")
+}
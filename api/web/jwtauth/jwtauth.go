@@ -0,0 +1,91 @@
+package jwtauth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/JhonX2011/GOWebApplication/api/web"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Option configures the optional behavior of the Middleware created with JWTAuth.
+type Option func(*options)
+
+type options struct {
+	parserOptions []jwt.ParserOption
+}
+
+// WithParserOptions sets the options used to parse and validate the tokens, for example
+// jwt.WithValidMethods, jwt.WithAudience or jwt.WithLeeway.
+func WithParserOptions(opts ...jwt.ParserOption) Option {
+	return func(o *options) {
+		o.parserOptions = append(o.parserOptions, opts...)
+	}
+}
+
+// JWTAuth returns a web.Middleware that authenticates the requests with the JWT carried in the
+// Authorization: Bearer header. The token signature is verified with the key returned by keyFunc
+// and its exp, nbf and iat claims are validated. The claims of a valid token are stored in the request
+// context, where handlers retrieve them with web.ClaimsFromContext. Requests without a token or with
+// an invalid or expired one are rejected with 401 Unauthorized. For example:
+//
+//	router.Use(jwtauth.JWTAuth(func(*jwt.Token) (interface{}, error) {
+//		return secret, nil
+//	}, jwtauth.WithParserOptions(jwt.WithValidMethods([]string{"HS256"}))))
+//
+// keyFunc should check the signing method of the token, or the valid methods be set with WithParserOptions,
+// so that a token can't pick a weaker algorithm. It lives in its own package so that the applications
+// not using JWT don't depend on it.
+func JWTAuth(keyFunc jwt.Keyfunc, opts ...Option) web.Middleware {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	parser := jwt.NewParser(o.parserOptions...)
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, "Bearer", "missing bearer token")
+				return
+			}
+
+			claims := jwt.MapClaims{}
+			if _, err := parser.ParseWithClaims(raw, claims, keyFunc); err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					unauthorized(w, _invalidToken, "expired bearer token")
+					return
+				}
+
+				web.LoggerFromContext(r.Context()).Debugf("Invalid bearer token | error: %s", err)
+				unauthorized(w, _invalidToken, "invalid bearer token")
+				return
+			}
+
+			next(w, r.WithContext(web.ContextWithClaims(r.Context(), claims)))
+		}
+	}
+}
+
+// bearerToken returns the token of the Authorization: Bearer header of r.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// _invalidToken is the WWW-Authenticate challenge of the requests carrying an invalid or expired token.
+const _invalidToken = `Bearer error="invalid_token"`
+
+// unauthorized responds with 401 Unauthorized and the given message, along with the WWW-Authenticate challenge.
+func unauthorized(w http.ResponseWriter, challenge, message string) {
+	w.Header().Set("WWW-Authenticate", challenge)
+	_ = web.EncodeJSON(w, web.NewError(http.StatusUnauthorized, message), http.StatusUnauthorized)
+}
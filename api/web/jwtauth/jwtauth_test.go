@@ -0,0 +1,131 @@
+package jwtauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/api/web"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = []byte("secret") //nolint:gochecknoglobals
+
+func keyFunc(*jwt.Token) (interface{}, error) {
+	return secret, nil
+}
+
+func givenToken(t *testing.T, method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTAuth(t *testing.T) {
+	valid := givenToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+		"sub": "user-42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	expired := givenToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+		"sub": "user-42",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	wrongKey := givenToken(t, jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"sub": "user-42"})
+	wrongMethod := givenToken(t, jwt.SigningMethodHS512, secret, jwt.MapClaims{"sub": "user-42"})
+
+	testCases := []struct {
+		name                 string
+		authorization        string
+		expectedStatus       int
+		expectedBody         string
+		expectedAuthenticate string
+		expectedSubject      string
+	}{
+		{
+			name:            "valid token",
+			authorization:   "Bearer " + valid,
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "user-42",
+		},
+		{
+			name:            "case insensitive scheme",
+			authorization:   "bearer " + valid,
+			expectedStatus:  http.StatusOK,
+			expectedSubject: "user-42",
+		},
+		{
+			name:                 "missing token",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"missing bearer token"}`,
+			expectedAuthenticate: "Bearer",
+		},
+		{
+			name:                 "basic auth",
+			authorization:        "Basic dXNlcjpwYXNz",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"missing bearer token"}`,
+			expectedAuthenticate: "Bearer",
+		},
+		{
+			name:                 "expired token",
+			authorization:        "Bearer " + expired,
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"expired bearer token"}`,
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:                 "malformed token",
+			authorization:        "Bearer not.a.jwt",
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"invalid bearer token"}`,
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:                 "invalid signature",
+			authorization:        "Bearer " + wrongKey,
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"invalid bearer token"}`,
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+		{
+			name:                 "invalid signing method",
+			authorization:        "Bearer " + wrongMethod,
+			expectedStatus:       http.StatusUnauthorized,
+			expectedBody:         `{"code":"unauthorized","message":"invalid bearer token"}`,
+			expectedAuthenticate: `Bearer error="invalid_token"`,
+		},
+	}
+
+	mw := JWTAuth(keyFunc, WithParserOptions(jwt.WithValidMethods([]string{"HS256"})))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var subject interface{}
+			h := mw(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := web.ClaimsFromContext(r.Context())
+				require.True(t, ok)
+				subject = claims["sub"]
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			if tc.authorization != "" {
+				r.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			require.Equal(t, tc.expectedStatus, w.Code)
+			require.Equal(t, tc.expectedAuthenticate, w.Header().Get("WWW-Authenticate"))
+			if tc.expectedBody != "" {
+				require.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+			if tc.expectedSubject != "" {
+				require.Equal(t, tc.expectedSubject, subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PreferredLanguage returns the language of supported that best matches the Accept-Language header
+// of the request, honoring the quality values. A language range matches a supported language either
+// exactly or by its primary subtag, so "en-US" matches "en" and vice versa. When nothing matches, the
+// first supported language is returned. It returns an empty string if supported is empty.
+func PreferredLanguage(r *http.Request, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	for _, tag := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if tag == "*" {
+			return supported[0]
+		}
+
+		for _, language := range supported {
+			if strings.EqualFold(tag, language) {
+				return language
+			}
+		}
+
+		for _, language := range supported {
+			if strings.EqualFold(primarySubtag(tag), primarySubtag(language)) {
+				return language
+			}
+		}
+	}
+
+	return supported[0]
+}
+
+// parseAcceptLanguage returns the language ranges of an Accept-Language header sorted by
+// descending quality. Ranges with the same quality keep their order, and ranges with
+// a quality of 0 or an invalid quality are discarded.
+func parseAcceptLanguage(header string) []string {
+	type weightedTag struct {
+		tag     string
+		quality float64
+	}
+
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		tags = append(tags, weightedTag{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+// primarySubtag returns the primary language subtag of a language tag, e.g. "en" for "en-US".
+func primarySubtag(tag string) string {
+	primary, _, _ := strings.Cut(tag, "-")
+	return primary
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		supported      []string
+		expected       string
+	}{
+		{
+			name:           "missing header returns the default language",
+			acceptLanguage: "",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "es",
+		},
+		{
+			name:           "highest quality wins regardless of order",
+			acceptLanguage: "en;q=0.5, pt;q=0.9, es;q=0.7",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "pt",
+		},
+		{
+			name:           "implicit quality is 1",
+			acceptLanguage: "en;q=0.8, pt",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "pt",
+		},
+		{
+			name:           "unsupported languages are skipped",
+			acceptLanguage: "fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "en",
+		},
+		{
+			name:           "region falls back to the primary subtag",
+			acceptLanguage: "pt-BR, en;q=0.8",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "pt",
+		},
+		{
+			name:           "exact region match is preferred",
+			acceptLanguage: "es-AR",
+			supported:      []string{"es-MX", "es-AR"},
+			expected:       "es-AR",
+		},
+		{
+			name:           "quality of zero is not acceptable",
+			acceptLanguage: "pt;q=0, en;q=0.1",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "en",
+		},
+		{
+			name:           "wildcard returns the default language",
+			acceptLanguage: "fr, *;q=0.5",
+			supported:      []string{"es", "en", "pt"},
+			expected:       "es",
+		},
+		{
+			name:           "no supported languages",
+			acceptLanguage: "en",
+			supported:      nil,
+			expected:       "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+
+			require.Equal(t, tc.expected, PreferredLanguage(r, tc.supported))
+		})
+	}
+}
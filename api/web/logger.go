@@ -0,0 +1,89 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+)
+
+// RequestIDHeader is the header carrying the ID of the request.
+const RequestIDHeader = "X-Request-Id"
+
+// defaultLogger is returned by LoggerFromContext when the request context carries no logger.
+var defaultLogger = logger.NewLogger(logger.DefaultOSExit) //nolint:gochecknoglobals
+
+// RequestLogger returns a Middleware that stores in the request context a child of base carrying
+// the request ID, taken from the RequestIDHeader when present, and the matched route.
+// Handlers retrieve it with LoggerFromContext to tie their log lines to the request.
+func RequestLogger(base logger.Logger) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			next(w, r.WithContext(context.WithValue(r.Context(), loggerKey, withRequestFields(base, r))))
+		}
+	}
+}
+
+// SlowRequestLogger returns a Middleware that times the next handlers and logs the method, path and duration
+// of the requests taking longer than threshold at Warning level with a child of l carrying the request ID
+// and the matched route, see RequestLogger. Faster requests are not logged.
+// Unlike RequestTimeout, slow requests are not interrupted.
+func SlowRequestLogger(l logger.Logger, threshold time.Duration) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			next(w, r)
+
+			if duration := time.Since(start); duration > threshold {
+				withRequestFields(l, r).Warningf("Slow request | method: %s | path: %s | duration: %s",
+					r.Method, r.URL.Path, duration)
+			}
+		}
+	}
+}
+
+// withRequestFields returns a child of l carrying the request ID and the matched route of r, if any.
+func withRequestFields(l logger.Logger, r *http.Request) logger.Logger {
+	fields := make(map[string]interface{})
+	if requestID := r.Header.Get(RequestIDHeader); requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if pattern := RoutePattern(r); pattern != "" {
+		fields["route"] = pattern
+	}
+
+	if len(fields) == 0 {
+		return l
+	}
+	return l.WithFields(fields)
+}
+
+// logError logs the error returned by the handler of r, at Warning level for client errors (4xx)
+// and at Error level otherwise.
+func logError(r *http.Request, err error) {
+	l, ok := r.Context().Value(loggerKey).(logger.Logger)
+	if !ok {
+		l = withRequestFields(defaultLogger, r)
+	}
+
+	status := http.StatusInternalServerError
+	if sc, ok := err.(StatusCoder); ok {
+		status = sc.StatusCode()
+	}
+
+	if status >= 400 && status <= 499 {
+		l.Warningf("Request failed | status: %d | error: %s", status, err)
+		return
+	}
+	l.Errorf("Request failed | status: %d | error: %s", status, err)
+}
+
+// LoggerFromContext returns the request-scoped logger stored by RequestLogger.
+// If the context carries no logger, a default logger is returned.
+func LoggerFromContext(ctx context.Context) logger.Logger {
+	if l, ok := ctx.Value(loggerKey).(logger.Logger); ok {
+		return l
+	}
+	return defaultLogger
+}
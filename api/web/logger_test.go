@@ -0,0 +1,141 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	output := new(bytes.Buffer)
+	base := logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())
+
+	r := New()
+	r.Use(RequestLogger(base))
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		LoggerFromContext(r.Context()).Info("fetching user")
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, strings.HasSuffix(output.String(), "| [fetching user] request_id=abc-123 route=/users/{id} \n"))
+}
+
+func TestRequestLogger_WithoutEnrichment(t *testing.T) {
+	base := logger.NewLogger(nil)
+
+	var got logger.Logger
+	h := RequestLogger(base)(func(w http.ResponseWriter, r *http.Request) {
+		got = LoggerFromContext(r.Context())
+	})
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Same(t, base, got)
+}
+
+func TestSlowRequestLogger(t *testing.T) {
+	output := new(bytes.Buffer)
+	base := logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())
+
+	r := New()
+	r.Use(SlowRequestLogger(base, 20*time.Millisecond))
+	r.Get("/fast", func(w http.ResponseWriter, r *http.Request) error {
+		return nil
+	})
+	r.Get("/slow/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.Empty(t, output.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/slow/42", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], " warning ")
+	require.Regexp(t, `\| Slow request \| method: GET \| path: /slow/42 \| duration: \d+(\.\d+)?ms `+
+		`request_id=abc-123 route=/slow/\{id\} $`, lines[0])
+}
+
+func TestLoggerFromContext_Default(t *testing.T) {
+	require.NotNil(t, LoggerFromContext(context.Background()))
+}
+
+func TestRouter_LogErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		logErrors     bool
+		expectedLevel string
+		expectedLine  string
+	}{
+		{
+			name:          "client error",
+			err:           NewError(http.StatusNotFound, "user 42 not found"),
+			logErrors:     true,
+			expectedLevel: "warning",
+			expectedLine:  "| Request failed | status: 404 | error: not_found: user 42 not found request_id=abc-123 route=/users/{id} \n",
+		},
+		{
+			name:          "server error",
+			err:           NewError(http.StatusServiceUnavailable, "database unavailable"),
+			logErrors:     true,
+			expectedLevel: "Error",
+			expectedLine: "| Request failed | status: 503 | error: service_unavailable: database unavailable " +
+				"request_id=abc-123 route=/users/{id} \n",
+		},
+		{
+			name:          "error without status",
+			err:           errors.New("unexpected failure"),
+			logErrors:     true,
+			expectedLevel: "Error",
+			expectedLine:  "| Request failed | status: 500 | error: unexpected failure request_id=abc-123 route=/users/{id} \n",
+		},
+		{
+			name:      "disabled",
+			err:       NewError(http.StatusServiceUnavailable, "database unavailable"),
+			logErrors: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			output := new(bytes.Buffer)
+			base := logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())
+
+			r := New()
+			r.Use(RequestLogger(base))
+			r.ErrorHandler(func(ctx context.Context, err error) {})
+			r.LogErrors(tc.logErrors)
+			r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+				return tc.err
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+			req.Header.Set(RequestIDHeader, "abc-123")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tc.expectedLine == "" {
+				require.Empty(t, output.String())
+				return
+			}
+			require.Contains(t, output.String(), " "+tc.expectedLevel+" ")
+			require.True(t, strings.HasSuffix(output.String(), tc.expectedLine), output.String())
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ContentType returns a Middleware that only lets through requests whose Content-Type
+// matches one of the given media types, responding with 415 Unsupported Media Type otherwise.
+// Parameters such as charset are ignored when comparing, and the check is skipped for methods
+// that don't carry a request body (GET, HEAD, OPTIONS and TRACE) and for the requests without body,
+// such as a DELETE or an empty POST.
+func ContentType(types ...string) Middleware {
+	allowed := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = struct{}{}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+				next(w, r)
+				return
+			}
+			if !hasBody(r) {
+				next(w, r)
+				return
+			}
+
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if _, ok := allowed[strings.ToLower(mediaType)]; err != nil || !ok {
+				err := NewErrorf(http.StatusUnsupportedMediaType, "unsupported content type %q, supported types: %s",
+					r.Header.Get("Content-Type"), strings.Join(types, ", "))
+				_ = EncodeJSON(w, err, http.StatusUnsupportedMediaType)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// hasBody reports whether r carries a request body, either with a positive Content-Length or chunked.
+func hasBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	return r.ContentLength != 0 || len(r.TransferEncoding) > 0
+}
+
+// RequireHTTPS returns a Middleware for services that must only be accessed over TLS. Requests forwarded
+// over plain HTTP, as reported by the X-Forwarded-Proto header set by the load balancer, are redirected with
+// 301 Moved Permanently to the same URL with the https scheme. Responses to HTTPS requests include the
+// Strict-Transport-Security header with the given max age, unless it is not positive.
+// Requests without X-Forwarded-Proto that didn't arrive over TLS, such as internal health checks
+// hitting the instance directly, are let through.
+func RequireHTTPS(hstsMaxAge time.Duration) Middleware {
+	hsts := fmt.Sprintf("max-age=%d", int64(hstsMaxAge.Seconds()))
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			proto := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Proto"), ",")[0]))
+			if proto == "http" {
+				target := url.URL{Scheme: "https", Host: r.Host, Path: r.URL.Path, RawPath: r.URL.RawPath, RawQuery: r.URL.RawQuery}
+				http.Redirect(w, r, target.String(), http.StatusMovedPermanently)
+				return
+			}
+
+			if (proto == "https" || r.TLS != nil) && hstsMaxAge > 0 {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// RequestTimeout returns a Middleware that applies the timeout requested by the client through the
+// X-Request-Timeout header, e.g. "1500ms" or "2s", as the deadline of the request context, so that
+// downstream calls such as database queries honor it. Timeouts longer than max are clamped to max,
+// and requests whose header isn't a positive duration are rejected with 400 Bad Request.
+// Requests without the header are let through unchanged.
+func RequestTimeout(max time.Duration) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			header := strings.TrimSpace(r.Header.Get("X-Request-Timeout"))
+			if header == "" {
+				next(w, r)
+				return
+			}
+
+			timeout, err := time.ParseDuration(header)
+			if err != nil || timeout <= 0 {
+				err := NewErrorf(http.StatusBadRequest, "invalid X-Request-Timeout %q: it must be a positive duration such as 2s", header)
+				_ = EncodeJSON(w, err, http.StatusBadRequest)
+				return
+			}
+
+			if max > 0 && timeout > max {
+				timeout = max
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// RequireAPIVersion returns a Middleware that only lets through requests whose Api-Version header is
+// one of the supported versions, responding with 400 Bad Request listing them otherwise.
+// The negotiated version is stored in the request context, so handlers can branch on APIVersion.
+func RequireAPIVersion(supported ...string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			version := strings.TrimSpace(r.Header.Get("Api-Version"))
+			if version == "" {
+				err := NewErrorf(http.StatusBadRequest, "missing Api-Version header, supported versions: %s",
+					strings.Join(supported, ", "))
+				_ = EncodeJSON(w, err, http.StatusBadRequest)
+				return
+			}
+
+			for _, v := range supported {
+				if v == version {
+					next(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey, v)))
+					return
+				}
+			}
+
+			err := NewErrorf(http.StatusBadRequest, "unsupported Api-Version %q, supported versions: %s",
+				version, strings.Join(supported, ", "))
+			_ = EncodeJSON(w, err, http.StatusBadRequest)
+		}
+	}
+}
+
+// APIVersion returns the version negotiated by RequireAPIVersion for the request.
+// It returns an empty string if the request didn't go through RequireAPIVersion.
+func APIVersion(r *http.Request) string {
+	version, _ := r.Context().Value(apiVersionKey).(string)
+	return version
+}
+
+// Recover returns a Middleware that recovers from the panics of the next handlers and responds with
+// 500 Internal Server Error and a generic JSON body, without leaking the panic or the stack to the client.
+// The panic is logged at Error level with the logger of the request context, see LoggerFromContext,
+// enriched with the method, path and ID of the request along with the stack trace.
+// http.ErrAbortHandler is not recovered, since it is meant to abort the response.
+// DefaultErrorHandler panics with the status code of the 5xx errors returned by the handlers, which Recover
+// would turn into a 500 response, so routers using Recover should set an ErrorHandler that doesn't panic.
+func Recover() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				fields := map[string]interface{}{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"stack":  string(debug.Stack()),
+				}
+				if requestID := r.Header.Get(RequestIDHeader); requestID != "" {
+					fields["request_id"] = requestID
+				}
+				LoggerFromContext(r.Context()).WithFields(fields).Errorf("Panic recovered | panic: %v", rec)
+
+				err := NewError(http.StatusInternalServerError, "internal server error")
+				_ = EncodeJSON(w, err, http.StatusInternalServerError)
+			}()
+
+			next(w, r)
+		}
+	}
+}
+
+// SecurityOptions are the values of the headers set by SecurityHeaders.
+// Empty values are replaced by the defaults.
+type SecurityOptions struct {
+	// ContentTypeOptions is the X-Content-Type-Options header. It defaults to nosniff.
+	ContentTypeOptions string
+	// FrameOptions is the X-Frame-Options header. It defaults to DENY.
+	FrameOptions string
+	// ReferrerPolicy is the Referrer-Policy header. It defaults to strict-origin-when-cross-origin.
+	ReferrerPolicy string
+	// ContentSecurityPolicy is the Content-Security-Policy header.
+	// It defaults to default-src 'self'; frame-ancestors 'none'.
+	ContentSecurityPolicy string
+}
+
+// withDefaults returns a copy of o where every empty value is replaced by its default.
+func (o SecurityOptions) withDefaults() SecurityOptions {
+	if o.ContentTypeOptions == "" {
+		o.ContentTypeOptions = "nosniff"
+	}
+	if o.FrameOptions == "" {
+		o.FrameOptions = "DENY"
+	}
+	if o.ReferrerPolicy == "" {
+		o.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	if o.ContentSecurityPolicy == "" {
+		o.ContentSecurityPolicy = "default-src 'self'; frame-ancestors 'none'"
+	}
+	return o
+}
+
+// SecurityHeaders returns a Middleware that sets the X-Content-Type-Options, X-Frame-Options, Referrer-Policy
+// and Content-Security-Policy headers of the responses, usually for routes serving a browser UI.
+// The headers are set before calling the next handlers, so they can still override them for a response.
+func SecurityHeaders(opts SecurityOptions) Middleware {
+	opts = opts.withDefaults()
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("X-Content-Type-Options", opts.ContentTypeOptions)
+			h.Set("X-Frame-Options", opts.FrameOptions)
+			h.Set("Referrer-Policy", opts.ReferrerPolicy)
+			h.Set("Content-Security-Policy", opts.ContentSecurityPolicy)
+
+			next(w, r)
+		}
+	}
+}
+
+// ServerHeader returns a Middleware that sets the Server header of the responses to the given value,
+// or removes it when the value is empty. Go's net/http doesn't send a Server header by itself, but handlers
+// may set one, for example a reverse proxy copying the headers of the upstream response, so the header is
+// applied again when the response headers are written, overriding the value set by the handler.
+func ServerHeader(value string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			sw := &serverHeaderWriter{ResponseWriter: w, value: value}
+			sw.apply()
+			next(sw, r)
+		}
+	}
+}
+
+// serverHeaderWriter applies the Server header right before the response headers are written.
+type serverHeaderWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *serverHeaderWriter) apply() {
+	if w.value == "" {
+		w.Header().Del("Server")
+		return
+	}
+	w.Header().Set("Server", w.value)
+}
+
+func (w *serverHeaderWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.apply()
+		// Informational responses are followed by the final one, whose headers are applied again.
+		w.wroteHeader = code >= http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *serverHeaderWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher when the underlying ResponseWriter does.
+func (w *serverHeaderWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying ResponseWriter, so http.ResponseController reaches its optional interfaces.
+func (w *serverHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,416 @@
+package web
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestContentType(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		contentType  string
+		noBody       bool
+		expectedCode int
+	}{
+		{
+			name:         "allowed content type",
+			method:       http.MethodPost,
+			contentType:  "application/json",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "allowed content type with parameters",
+			method:       http.MethodPut,
+			contentType:  "Application/JSON; charset=utf-8",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "rejected content type",
+			method:       http.MethodPost,
+			contentType:  "text/plain",
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "missing content type",
+			method:       http.MethodPatch,
+			contentType:  "",
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "method without body is not checked",
+			method:       http.MethodGet,
+			contentType:  "text/plain",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "request without body is not checked",
+			method:       http.MethodDelete,
+			noBody:       true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "empty POST is not checked",
+			method:       http.MethodPost,
+			noBody:       true,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := ContentType("application/json")(okHandler)
+
+			body := io.Reader(strings.NewReader(`{}`))
+			if tc.noBody {
+				body = nil
+			}
+			r := httptest.NewRequest(tc.method, "/", body)
+			if tc.contentType != "" {
+				r.Header.Set("Content-Type", tc.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			h(w, r)
+			require.Equal(t, tc.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestContentType_RejectedBody(t *testing.T) {
+	h := ContentType("application/json")(okHandler)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("foo"))
+	r.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+
+	h(w, r)
+	require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	require.JSONEq(t, `{"code":"unsupported_media_type","message":"unsupported content type \"text/plain\", supported types: application/json"}`, w.Body.String())
+}
+
+func TestRequireHTTPS(t *testing.T) {
+	testCases := []struct {
+		name             string
+		url              string
+		forwardedProto   string
+		tls              bool
+		expectedCode     int
+		expectedLocation string
+		expectedHSTS     string
+	}{
+		{
+			name:             "redirects plain HTTP",
+			url:              "http://api.example.com/users/42?fields=name",
+			forwardedProto:   "http",
+			expectedCode:     http.StatusMovedPermanently,
+			expectedLocation: "https://api.example.com/users/42?fields=name",
+		},
+		{
+			name:           "forwarded HTTPS",
+			url:            "http://api.example.com/users/42",
+			forwardedProto: "https",
+			expectedCode:   http.StatusOK,
+			expectedHSTS:   "max-age=31536000",
+		},
+		{
+			name:           "first forwarded proto",
+			url:            "http://api.example.com/users/42",
+			forwardedProto: "HTTPS, http",
+			expectedCode:   http.StatusOK,
+			expectedHSTS:   "max-age=31536000",
+		},
+		{
+			name:         "TLS",
+			url:          "https://api.example.com/users/42",
+			tls:          true,
+			expectedCode: http.StatusOK,
+			expectedHSTS: "max-age=31536000",
+		},
+		{
+			name:         "direct request",
+			url:          "http://10.0.0.1:8080/ping",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.forwardedProto != "" {
+				r.Header.Set("X-Forwarded-Proto", tc.forwardedProto)
+			}
+			if !tc.tls {
+				r.TLS = nil
+			}
+			w := httptest.NewRecorder()
+
+			RequireHTTPS(365*24*time.Hour)(okHandler)(w, r)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedLocation, w.Header().Get("Location"))
+			require.Equal(t, tc.expectedHSTS, w.Header().Get("Strict-Transport-Security"))
+		})
+	}
+}
+
+func TestRequireHTTPS_WithoutHSTS(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "https://api.example.com/users/42", nil)
+	w := httptest.NewRecorder()
+
+	RequireHTTPS(0)(okHandler)(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Strict-Transport-Security"))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	testCases := []struct {
+		name          string
+		header        string
+		expectedCode  int
+		expectedLimit time.Duration
+	}{
+		{
+			name:          "valid timeout",
+			header:        "2s",
+			expectedCode:  http.StatusOK,
+			expectedLimit: 2 * time.Second,
+		},
+		{
+			name:          "timeout exceeding the max is clamped",
+			header:        "1m",
+			expectedCode:  http.StatusOK,
+			expectedLimit: 5 * time.Second,
+		},
+		{
+			name:         "invalid timeout",
+			header:       "soon",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative timeout",
+			header:       "-1s",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "missing header",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				deadline    time.Time
+				hasDeadline bool
+			)
+			h := RequestTimeout(5 * time.Second)(func(w http.ResponseWriter, r *http.Request) {
+				deadline, hasDeadline = r.Context().Deadline()
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set("X-Request-Timeout", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			start := time.Now()
+			h(w, r)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedLimit != 0, hasDeadline)
+			if hasDeadline {
+				require.WithinDuration(t, start.Add(tc.expectedLimit), deadline, time.Second)
+			}
+		})
+	}
+}
+
+func TestRequireAPIVersion(t *testing.T) {
+	testCases := []struct {
+		name            string
+		header          string
+		expectedCode    int
+		expectedBody    string
+		expectedVersion string
+	}{
+		{
+			name:            "valid version",
+			header:          "2024-05-01",
+			expectedCode:    http.StatusOK,
+			expectedVersion: "2024-05-01",
+		},
+		{
+			name:         "missing version",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"code":"bad_request","message":"missing Api-Version header, supported versions: 2023-10-01, 2024-05-01"}`,
+		},
+		{
+			name:         "unsupported version",
+			header:       "2022-01-01",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"code":"bad_request","message":"unsupported Api-Version \"2022-01-01\", supported versions: 2023-10-01, 2024-05-01"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var version string
+			h := RequireAPIVersion("2023-10-01", "2024-05-01")(func(w http.ResponseWriter, r *http.Request) {
+				version = APIVersion(r)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				r.Header.Set("Api-Version", tc.header)
+			}
+			w := httptest.NewRecorder()
+
+			h(w, r)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedVersion, version)
+			if tc.expectedBody != "" {
+				require.JSONEq(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIVersion_WithoutMiddleware(t *testing.T) {
+	require.Empty(t, APIVersion(httptest.NewRequest(http.MethodGet, "/", nil)))
+}
+
+func TestRecover(t *testing.T) {
+	output := new(bytes.Buffer)
+	l := logger.NewLogger(nil, logger.WithOutput(output), logger.WithFormat(logger.FormatLogfmt))
+
+	h := wrapMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map")
+	}, []Middleware{RequestLogger(l), Recover()})
+
+	r := httptest.NewRequest(http.MethodPost, "/users/42?secret=1", nil)
+	r.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+
+	require.NotPanics(t, func() { h(w, r) })
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.JSONEq(t, `{"code":"internal_server_error","message":"internal server error"}`, w.Body.String())
+	require.NotContains(t, w.Body.String(), "nil map")
+
+	require.Contains(t, output.String(), ` level=error `)
+	require.Contains(t, output.String(), ` msg="Panic recovered | panic: nil map"`)
+	require.Contains(t, output.String(), ` method=POST `)
+	require.Contains(t, output.String(), ` path=/users/42 `)
+	require.Contains(t, output.String(), ` request_id=req-1 `)
+	require.Contains(t, output.String(), ` stack="goroutine `)
+}
+
+func TestRecover_AbortHandler(t *testing.T) {
+	h := Recover()(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     SecurityOptions
+		expected http.Header
+	}{
+		{
+			name: "defaults",
+			expected: http.Header{
+				"X-Content-Type-Options":  {"nosniff"},
+				"X-Frame-Options":         {"DENY"},
+				"Referrer-Policy":         {"strict-origin-when-cross-origin"},
+				"Content-Security-Policy": {"default-src 'self'; frame-ancestors 'none'"},
+			},
+		},
+		{
+			name: "overrides",
+			opts: SecurityOptions{
+				FrameOptions:          "SAMEORIGIN",
+				ReferrerPolicy:        "no-referrer",
+				ContentSecurityPolicy: "default-src 'self'; img-src 'self' data:",
+			},
+			expected: http.Header{
+				"X-Content-Type-Options":  {"nosniff"},
+				"X-Frame-Options":         {"SAMEORIGIN"},
+				"Referrer-Policy":         {"no-referrer"},
+				"Content-Security-Policy": {"default-src 'self'; img-src 'self' data:"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			SecurityHeaders(tc.opts)(okHandler)(rec, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, tc.expected, rec.Result().Header)
+		})
+	}
+}
+
+func TestSecurityHeaders_HandlerOverride(t *testing.T) {
+	h := SecurityHeaders(SecurityOptions{})(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/admin/embed", nil))
+
+	require.Equal(t, "SAMEORIGIN", rec.Header().Get("X-Frame-Options"))
+	require.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+}
+
+func TestServerHeader(t *testing.T) {
+	handlers := map[string]http.HandlerFunc{
+		"write header": okHandler,
+		"write body": func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		},
+		"handler sets the header": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "upstream/1.2")
+			w.WriteHeader(http.StatusOK)
+		},
+		"flush": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "upstream/1.2")
+			require.NoError(t, http.NewResponseController(w).Flush())
+		},
+	}
+
+	for name, h := range handlers {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ServerHeader("payments")(h)(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, []string{"payments"}, rec.Result().Header.Values("Server"))
+
+			rec = httptest.NewRecorder()
+			ServerHeader("")(h)(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			_, ok := rec.Result().Header["Server"]
+			require.False(t, ok)
+		})
+	}
+}
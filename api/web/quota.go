@@ -0,0 +1,123 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaUsage is the usage of the quota of a client after counting a request.
+type QuotaUsage struct {
+	// Allowed reports whether the request is within the quota.
+	Allowed bool
+	// Limit is the number of requests the client can make in the quota period.
+	Limit int64
+	// Remaining is the number of requests the client can still make in the quota period.
+	Remaining int64
+	// Reset is when the quota period ends and the count starts over.
+	Reset time.Time
+}
+
+// QuotaStore counts the requests made by every client in the current quota period.
+// MemoryQuotaStore keeps the counts in the memory of the instance, so services running several
+// instances need a shared store, for example one backed by Redis.
+type QuotaStore interface {
+	// Increment counts a request of the client identified by key and returns the resulting usage of its quota.
+	Increment(ctx context.Context, key string) (QuotaUsage, error)
+}
+
+// Quota returns a Middleware that counts every request in store under the client key returned by keyFn,
+// for example the API key, and responds with 429 Too Many Requests once the quota of the client is exhausted.
+// The responses include the X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset (Unix time) headers, and the
+// rejected ones also Retry-After. Requests for which keyFn returns an empty key are not counted.
+// If the store fails, the request is let through and the error is logged, so an unavailable store doesn't
+// take the API down.
+func Quota(store QuotaStore, keyFn func(*http.Request) string) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := keyFn(r)
+			if key == "" {
+				next(w, r)
+				return
+			}
+
+			usage, err := store.Increment(r.Context(), key)
+			if err != nil {
+				LoggerFromContext(r.Context()).Errorf("Failed to count request quota, letting it through | error: %s", err)
+				next(w, r)
+				return
+			}
+
+			w.Header().Set("X-Quota-Limit", strconv.FormatInt(usage.Limit, 10))
+			w.Header().Set("X-Quota-Remaining", strconv.FormatInt(usage.Remaining, 10))
+			w.Header().Set("X-Quota-Reset", strconv.FormatInt(usage.Reset.Unix(), 10))
+
+			if !usage.Allowed {
+				retryAfter := int64(time.Until(usage.Reset).Seconds()) + 1
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+				err := NewErrorf(http.StatusTooManyRequests, "quota of %d requests exhausted, it resets at %s",
+					usage.Limit, usage.Reset.UTC().Format(time.RFC3339))
+				_ = EncodeJSON(w, err, http.StatusTooManyRequests)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// MemoryQuotaStore is a QuotaStore that keeps the counts in memory, allowing the same number of requests
+// to every client each calendar month in UTC. The counts are lost when the process restarts.
+type MemoryQuotaStore struct {
+	limit int64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	reset  time.Time
+	counts map[string]int64
+}
+
+// NewMemoryQuotaStore returns a MemoryQuotaStore allowing limit requests per client each calendar month.
+func NewMemoryQuotaStore(limit int64) *MemoryQuotaStore {
+	return &MemoryQuotaStore{
+		limit:  limit,
+		now:    time.Now,
+		counts: make(map[string]int64),
+	}
+}
+
+// Increment implements the QuotaStore interface.
+func (s *MemoryQuotaStore) Increment(_ context.Context, key string) (QuotaUsage, error) {
+	now := s.now().UTC()
+	reset := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Every client shares the same period, so the counts of the previous one are dropped at once.
+	if !reset.Equal(s.reset) {
+		s.reset = reset
+		s.counts = make(map[string]int64)
+	}
+
+	s.counts[key]++
+	count := s.counts[key]
+
+	remaining := s.limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return QuotaUsage{
+		Allowed:   count <= s.limit,
+		Limit:     s.limit,
+		Remaining: remaining,
+		Reset:     reset,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func apiKey(r *http.Request) string {
+	return r.Header.Get("X-Api-Key")
+}
+
+type failingQuotaStore struct{}
+
+func (failingQuotaStore) Increment(context.Context, string) (QuotaUsage, error) {
+	return QuotaUsage{}, errors.New("connection refused")
+}
+
+func TestQuota(t *testing.T) {
+	now := time.Date(2024, 5, 31, 23, 59, 0, 0, time.UTC)
+	store := NewMemoryQuotaStore(2)
+	store.now = func() time.Time { return now }
+	h := Quota(store, apiKey)(okHandler)
+
+	request := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			r.Header.Set("X-Api-Key", key)
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	w := request("foo")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get("X-Quota-Limit"))
+	require.Equal(t, "1", w.Header().Get("X-Quota-Remaining"))
+	require.Equal(t, "1717200000", w.Header().Get("X-Quota-Reset"))
+
+	w = request("foo")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+
+	w = request("foo")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"code":"too_many_requests","message":"quota of 2 requests exhausted, it resets at 2024-06-01T00:00:00Z"}`,
+		w.Body.String())
+
+	// The quota of every client is independent.
+	w = request("bar")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("X-Quota-Remaining"))
+
+	// Requests without a client key are not counted.
+	w = request("")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("X-Quota-Limit"))
+
+	// The quota starts over in the next month.
+	now = now.Add(time.Minute)
+	w = request("foo")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "1", w.Header().Get("X-Quota-Remaining"))
+	require.Equal(t, "1719792000", w.Header().Get("X-Quota-Reset"))
+}
+
+func TestQuota_StoreError(t *testing.T) {
+	h := Quota(failingQuotaStore{}, apiKey)(okHandler)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Api-Key", "foo")
+	w := httptest.NewRecorder()
+
+	h(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("X-Quota-Limit"))
+}
@@ -1,16 +1,137 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// _maxPooledBufferSize is the capacity above which buffers are not returned to the pool,
+// so a few large responses don't keep the memory retained.
+const _maxPooledBufferSize = 64 << 10
+
+const (
+	_jsonContentType    = "application/json; charset=utf-8"
+	_problemContentType = "application/problem+json"
+)
+
+// bufferPool holds the buffers used by EncodeJSON to marshal the responses.
+var bufferPool = sync.Pool{ //nolint:gochecknoglobals
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
 type Headers interface {
 	Headers() http.Header
 }
 
+// ResponseWrapper, when set, is applied by EncodeJSON to every successful (2xx) response value
+// before marshalling it, for example to wrap it in a {"data": ..., "meta": ...} envelope.
+// It is not applied to []byte and io.Reader values nor to 204 No Content responses.
+var ResponseWrapper func(v interface{}) interface{} //nolint:gochecknoglobals
+
+// JSONMarshal, when set, replaces the default encoding/json marshalling of the response values
+// done by EncodeJSON, for example with MarshalOmitNull to omit the null fields of every response.
+// It is not used for []byte and io.Reader values, which are written as is.
+var JSONMarshal func(v interface{}) ([]byte, error) //nolint:gochecknoglobals
+
+// SetContentLength, when true, makes EncodeJSON set the Content-Length header of the responses, so
+// clients receive the length up front instead of a chunked body. EncodeJSON already marshals the whole
+// body before writing it, but keeping large bodies buffered to know their length trades memory for the
+// header, so it is disabled by default. Without it, net/http only sets Content-Length for small bodies.
+var SetContentLength bool //nolint:gochecknoglobals
+
+// EncodeJSON writes v as the JSON body of the response with the given status code.
+// It returns an error without writing anything when code is not a valid HTTP status code.
 func EncodeJSON(w http.ResponseWriter, v interface{}, code int) error {
+	return encodeJSON(w, nil, v, code, _jsonContentType)
+}
+
+// EncodeJSONWithRequest works like EncodeJSON, but it returns the error of the context of r without marshalling
+// nor writing v when the context is already done, typically because the client disconnected, so no work
+// is wasted on a response nobody reads.
+func EncodeJSONWithRequest(w http.ResponseWriter, r *http.Request, v interface{}, code int) error {
+	if err := r.Context().Err(); err != nil {
+		return err
+	}
+	return EncodeJSON(w, v, code)
+}
+
+// EncodeJSONCacheable works like EncodeJSON, but for 200 OK responses it also sets an ETag header computed
+// from the JSON body. When the If-None-Match header of a GET or HEAD request matches the ETag, it responds
+// with 304 Not Modified without writing the body, so the client can reuse its cached copy.
+func EncodeJSONCacheable(w http.ResponseWriter, r *http.Request, v interface{}, code int) error {
+	return encodeJSON(w, r, v, code, _jsonContentType)
+}
+
+// Pagination is the pagination metadata of the responses written by EncodePaginated.
+type Pagination struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// paginatedResponse is the body of the responses written by EncodePaginated.
+type paginatedResponse struct {
+	Items      interface{} `json:"items"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// EncodePaginated writes items as a page of results along with the pagination metadata, with the body
+// {"items": ..., "pagination": {"page": ..., "page_size": ..., "total": ..., "total_pages": ...}},
+// where total is the number of results across all the pages. It is written with EncodeJSON, so
+// ResponseWrapper and JSONMarshal also apply. total_pages is 0 when pageSize is not positive.
+func EncodePaginated(w http.ResponseWriter, items interface{}, page, pageSize, total int, code int) error {
+	pagination := Pagination{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}
+	if pageSize > 0 {
+		pagination.TotalPages = (total + pageSize - 1) / pageSize
+	}
+
+	return EncodeJSON(w, paginatedResponse{Items: items, Pagination: pagination}, code)
+}
+
+// Problem is the body of an RFC 7807 Problem Details error response, written by EncodeProblem.
+type Problem struct {
+	// Type is a URI reference identifying the problem type. When empty, it is "about:blank" by definition.
+	Type string `json:"type,omitempty"`
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code. EncodeProblem sets it to the status code of the response when it is 0.
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference identifying the specific occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+}
+
+// EncodeProblem writes p as an RFC 7807 Problem Details response with the application/problem+json
+// content type and the given status code. It is written like EncodeJSON, except for the content type.
+func EncodeProblem(w http.ResponseWriter, p Problem, code int) error {
+	if p.Status == 0 {
+		p.Status = code
+	}
+	return encodeJSON(w, nil, p, code, _problemContentType)
+}
+
+// encodeJSON implements EncodeJSON, along with the conditional GET handling of EncodeJSONCacheable when r is not nil,
+// writing the body with the given content type.
+func encodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, code int, contentType string) error {
+	if err := validateStatusCode(code); err != nil {
+		return err
+	}
+
 	if headers, ok := v.(Headers); ok {
 		for k, values := range headers.Headers() {
 			for _, v := range values {
@@ -33,14 +154,42 @@ func EncodeJSON(w http.ResponseWriter, v interface{}, code int) error {
 	case io.Reader:
 		jsonData, err = io.ReadAll(v)
 	default:
-		jsonData, err = json.Marshal(v)
+		if ResponseWrapper != nil && code >= 200 && code <= 299 {
+			v = ResponseWrapper(v)
+		}
+
+		if JSONMarshal != nil {
+			jsonData, err = JSONMarshal(v)
+			break
+		}
+
+		buf := bufferPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer putBuffer(buf)
+
+		// Unlike json.Marshal, the encoder appends a newline, which is trimmed to keep the same body.
+		err = json.NewEncoder(buf).Encode(v)
+		jsonData = bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
 	}
 
 	if err != nil {
 		return err
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r != nil && code == http.StatusOK {
+		etag := computeETag(jsonData)
+		w.Header().Set("ETag", etag)
+
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) && etagMatch(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if SetContentLength {
+		w.Header().Set("Content-Length", strconv.Itoa(len(jsonData)))
+	}
 
 	w.WriteHeader(code)
 
@@ -50,3 +199,59 @@ func EncodeJSON(w http.ResponseWriter, v interface{}, code int) error {
 
 	return nil
 }
+
+// computeETag returns a strong ETag computed from the hash of body.
+func computeETag(body []byte) string {
+	h := fnv.New64a()
+	h.Write(body) //nolint:errcheck
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// etagMatch reports whether the If-None-Match header value matches etag, using the weak comparison
+// required for If-None-Match, that is, ignoring the W/ prefix.
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// putBuffer returns buf to the pool unless it grew too large.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= _maxPooledBufferSize {
+		bufferPool.Put(buf)
+	}
+}
+
+// Stream copies r into the body of the response with the given content type and status code.
+// Unlike EncodeJSON, which always responds with application/json, it is meant for non JSON payloads
+// such as CSV exports or files, and it doesn't buffer the body in memory.
+func Stream(w http.ResponseWriter, r io.Reader, contentType string, code int) error {
+	if err := validateStatusCode(code); err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.WriteHeader(code)
+
+	if _, err := io.Copy(w, r); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateStatusCode checks that code is within the range of valid HTTP status codes,
+// since http.ResponseWriter.WriteHeader panics when it is not.
+func validateStatusCode(code int) error {
+	if code < 100 || code > 599 {
+		return fmt.Errorf("invalid HTTP status code %d: it must be between 100 and 599", code)
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeJSON(w, map[string]string{"message": "pong"}, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{"message":"pong"}`, w.Body.String())
+}
+
+func TestEncodeJSON_NoContent(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeJSON(w, map[string]string{"message": "pong"}, http.StatusNoContent)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Empty(t, w.Body.String())
+}
+
+func TestEncodeJSON_InvalidStatusCode(t *testing.T) {
+	testCases := []struct {
+		name       string
+		code       int
+		errMessage string
+	}{
+		{
+			name:       "zero",
+			code:       0,
+			errMessage: "invalid HTTP status code 0: it must be between 100 and 599",
+		},
+		{
+			name:       "below range",
+			code:       99,
+			errMessage: "invalid HTTP status code 99: it must be between 100 and 599",
+		},
+		{
+			name:       "above range",
+			code:       600,
+			errMessage: "invalid HTTP status code 600: it must be between 100 and 599",
+		},
+		{
+			name:       "typo",
+			code:       2000,
+			errMessage: "invalid HTTP status code 2000: it must be between 100 and 599",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := EncodeJSON(w, "pong", tc.code)
+			require.EqualError(t, err, tc.errMessage)
+			require.False(t, w.Flushed)
+			require.Empty(t, w.Header())
+			require.Empty(t, w.Body.String())
+		})
+	}
+}
+
+func givenResponseWrapper(t *testing.T) {
+	ResponseWrapper = func(v interface{}) interface{} {
+		return map[string]interface{}{
+			"data": v,
+			"meta": map[string]string{"version": "v1"},
+		}
+	}
+	t.Cleanup(func() {
+		ResponseWrapper = nil
+	})
+}
+
+func TestEncodeJSON_ResponseWrapper(t *testing.T) {
+	givenResponseWrapper(t)
+	w := httptest.NewRecorder()
+
+	err := EncodeJSON(w, map[string]string{"message": "pong"}, http.StatusOK)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"data":{"message":"pong"},"meta":{"version":"v1"}}`, w.Body.String())
+}
+
+func TestEncodeJSON_SetContentLength(t *testing.T) {
+	SetContentLength = true
+	t.Cleanup(func() {
+		SetContentLength = false
+	})
+
+	// The body is larger than the buffer below which net/http sets Content-Length on its own.
+	body := map[string]string{"message": strings.Repeat("a", 8<<10)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = EncodeJSON(w, body, http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	expected, err := json.Marshal(body)
+	require.NoError(t, err)
+	require.Equal(t, strconv.Itoa(len(expected)), res.Header.Get("Content-Length"))
+	require.Equal(t, int64(len(expected)), res.ContentLength)
+	require.Empty(t, res.TransferEncoding)
+}
+
+func TestEncodeJSON_WithoutContentLength(t *testing.T) {
+	body := map[string]string{"message": strings.Repeat("a", 8<<10)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = EncodeJSON(w, body, http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Empty(t, res.Header.Get("Content-Length"))
+	require.Equal(t, []string{"chunked"}, res.TransferEncoding)
+}
+
+func TestEncodePaginated(t *testing.T) {
+	testCases := []struct {
+		name     string
+		page     int
+		pageSize int
+		total    int
+		expected string
+	}{
+		{
+			name:     "exact last page",
+			page:     1,
+			pageSize: 2,
+			total:    4,
+			expected: `{"items":["a","b"],"pagination":{"page":1,"page_size":2,"total":4,"total_pages":2}}`,
+		},
+		{
+			name:     "partial last page",
+			page:     1,
+			pageSize: 2,
+			total:    5,
+			expected: `{"items":["a","b"],"pagination":{"page":1,"page_size":2,"total":5,"total_pages":3}}`,
+		},
+		{
+			name:     "no results",
+			page:     1,
+			pageSize: 2,
+			total:    0,
+			expected: `{"items":["a","b"],"pagination":{"page":1,"page_size":2,"total":0,"total_pages":0}}`,
+		},
+		{
+			name:     "invalid page size",
+			page:     1,
+			pageSize: 0,
+			total:    5,
+			expected: `{"items":["a","b"],"pagination":{"page":1,"page_size":0,"total":5,"total_pages":0}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := EncodePaginated(w, []string{"a", "b"}, tc.page, tc.pageSize, tc.total, http.StatusOK)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.JSONEq(t, tc.expected, w.Body.String())
+		})
+	}
+}
+
+func TestEncodeProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeProblem(w, Problem{
+		Type:     "https://example.com/problems/out-of-credit",
+		Title:    "You do not have enough credit.",
+		Detail:   "Your current balance is 30, but that costs 50.",
+		Instance: "/account/12345/msgs/abc",
+	}, http.StatusForbidden)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	require.JSONEq(t, `{
+		"type": "https://example.com/problems/out-of-credit",
+		"title": "You do not have enough credit.",
+		"status": 403,
+		"detail": "Your current balance is 30, but that costs 50.",
+		"instance": "/account/12345/msgs/abc"
+	}`, w.Body.String())
+}
+
+func TestEncodeProblem_OmitsEmptyFields(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeProblem(w, Problem{Title: "Service Unavailable", Status: 503}, http.StatusServiceUnavailable)
+	require.NoError(t, err)
+	require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	require.Equal(t, `{"title":"Service Unavailable","status":503}`, w.Body.String())
+}
+
+func TestEncodeJSON_JSONMarshal(t *testing.T) {
+	JSONMarshal = MarshalOmitNull
+	t.Cleanup(func() {
+		JSONMarshal = nil
+	})
+
+	type user struct {
+		ID       int     `json:"id"`
+		Nickname *string `json:"nickname"`
+		Email    *string `json:"email"`
+	}
+	email := "john@example.com"
+
+	w := httptest.NewRecorder()
+	err := EncodeJSON(w, user{ID: 42, Email: &email}, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":42,"email":"john@example.com"}`, w.Body.String())
+
+	// Raw bodies are written as is.
+	w = httptest.NewRecorder()
+	err = EncodeJSON(w, []byte(`{"nickname":null}`), http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, `{"nickname":null}`, w.Body.String())
+}
+
+func TestEncodeJSON_ResponseWrapperBypass(t *testing.T) {
+	testCases := []struct {
+		name         string
+		value        interface{}
+		code         int
+		expectedBody string
+	}{
+		{
+			name:         "bytes",
+			value:        []byte(`{"message":"pong"}`),
+			code:         http.StatusOK,
+			expectedBody: `{"message":"pong"}`,
+		},
+		{
+			name:         "reader",
+			value:        strings.NewReader(`{"message":"pong"}`),
+			code:         http.StatusOK,
+			expectedBody: `{"message":"pong"}`,
+		},
+		{
+			name:         "no content",
+			value:        map[string]string{"message": "pong"},
+			code:         http.StatusNoContent,
+			expectedBody: ``,
+		},
+		{
+			name:         "error response",
+			value:        NewError(http.StatusNotFound, "resource not found"),
+			code:         http.StatusNotFound,
+			expectedBody: `{"code":"not_found","message":"resource not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			givenResponseWrapper(t)
+			w := httptest.NewRecorder()
+
+			err := EncodeJSON(w, tc.value, tc.code)
+			require.NoError(t, err)
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	csv := "id,name\n1,foo\n2,bar\n"
+
+	err := Stream(w, strings.NewReader(csv), "text/csv; charset=utf-8", http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, csv, w.Body.String())
+}
+
+func TestStream_InvalidStatusCode(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := Stream(w, strings.NewReader("id,name\n"), "text/csv", 2000)
+	require.EqualError(t, err, "invalid HTTP status code 2000: it must be between 100 and 599")
+	require.Empty(t, w.Header())
+	require.Empty(t, w.Body.String())
+}
+
+// countingMarshaler counts the times it is marshalled.
+type countingMarshaler struct {
+	calls int
+}
+
+func (m *countingMarshaler) MarshalJSON() ([]byte, error) {
+	m.calls++
+	return []byte(`{"id":"42"}`), nil
+}
+
+func TestEncodeJSONWithRequest(t *testing.T) {
+	v := &countingMarshaler{}
+
+	w := httptest.NewRecorder()
+	err := EncodeJSONWithRequest(w, httptest.NewRequest(http.MethodGet, "/users/42", nil), v, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"id":"42"}`, w.Body.String())
+	require.Equal(t, 1, v.calls)
+}
+
+func TestEncodeJSONWithRequest_CanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	v := &countingMarshaler{}
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil).WithContext(ctx)
+
+	w := httptest.NewRecorder()
+	err := EncodeJSONWithRequest(w, r, v, http.StatusOK)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, v.calls)
+	require.False(t, w.Flushed)
+	require.Empty(t, w.Header())
+	require.Empty(t, w.Body.String())
+}
+
+func TestEncodeJSONCacheable(t *testing.T) {
+	v := map[string]string{"id": "42", "name": "John"}
+
+	w := httptest.NewRecorder()
+	err := EncodeJSONCacheable(w, httptest.NewRequest(http.MethodGet, "/users/42", nil), v, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `{"id":"42","name":"John"}`, w.Body.String())
+
+	etag := w.Header().Get("ETag")
+	require.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+
+	testCases := []struct {
+		name         string
+		method       string
+		ifNoneMatch  string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "match",
+			method:       http.MethodGet,
+			ifNoneMatch:  etag,
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "weak match in list",
+			method:       http.MethodGet,
+			ifNoneMatch:  `"abc", W/` + etag,
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "wildcard",
+			method:       http.MethodHead,
+			ifNoneMatch:  "*",
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "mismatch",
+			method:       http.MethodGet,
+			ifNoneMatch:  `"0000000000000000"`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":"42","name":"John"}`,
+		},
+		{
+			name:         "not a conditional GET",
+			method:       http.MethodPut,
+			ifNoneMatch:  etag,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":"42","name":"John"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, "/users/42", nil)
+			r.Header.Set("If-None-Match", tc.ifNoneMatch)
+
+			w := httptest.NewRecorder()
+			err := EncodeJSONCacheable(w, r, v, http.StatusOK)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedBody, w.Body.String())
+			require.Equal(t, etag, w.Header().Get("ETag"))
+		})
+	}
+}
+
+func TestEncodeJSONCacheable_NotOK(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/users", nil)
+	r.Header.Set("If-None-Match", "*")
+
+	w := httptest.NewRecorder()
+	err := EncodeJSONCacheable(w, r, map[string]string{"id": "42"}, http.StatusCreated)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.Empty(t, w.Header().Get("ETag"))
+}
+
+func TestEncodeJSON_MarshalError(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := EncodeJSON(w, map[string]interface{}{"fn": func() {}}, http.StatusOK)
+	require.EqualError(t, err, "json: unsupported type: func()")
+	require.Empty(t, w.Body.String())
+
+	// The buffer returned to the pool after the failure must not leak into the next response.
+	w = httptest.NewRecorder()
+	err = EncodeJSON(w, map[string]string{"message": "pong"}, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, `{"message":"pong"}`, w.Body.String())
+}
+
+func TestEncodeJSON_SameBodyAsMarshal(t *testing.T) {
+	v := map[string]interface{}{"html": "<a href=\"/\">home</a>", "items": []int{1, 2, 3}}
+	expected, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, EncodeJSON(w, v, http.StatusOK))
+	require.Equal(t, string(expected), w.Body.String())
+}
+
+type benchmarkResponse struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email"`
+	Tags  []string `json:"tags"`
+}
+
+// discardResponseWriter is a http.ResponseWriter that discards the body, so the benchmarks
+// only measure the allocations of the encoding.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w *discardResponseWriter) WriteHeader(int) {}
+
+var benchmarkValue = benchmarkResponse{ //nolint:gochecknoglobals
+	ID:    42,
+	Name:  "John Doe",
+	Email: "john.doe@example.com",
+	Tags:  []string{"admin", "beta", "premium"},
+}
+
+func BenchmarkEncodeJSON(b *testing.B) {
+	w := &discardResponseWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := EncodeJSON(w, benchmarkValue, http.StatusOK); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEncodeJSON_Marshal is the baseline of marshalling into a fresh buffer on every call.
+func BenchmarkEncodeJSON_Marshal(b *testing.B) {
+	w := &discardResponseWriter{header: make(http.Header)}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(benchmarkValue)
+		if err != nil {
+			b.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
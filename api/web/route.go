@@ -0,0 +1,92 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// _routeMethods are the methods accepted by Register, the ones the chi router supports by default.
+var _routeMethods = map[string]struct{}{ //nolint:gochecknoglobals
+	http.MethodGet:     {},
+	http.MethodHead:    {},
+	http.MethodPost:    {},
+	http.MethodPut:     {},
+	http.MethodPatch:   {},
+	http.MethodDelete:  {},
+	http.MethodConnect: {},
+	http.MethodOptions: {},
+	http.MethodTrace:   {},
+}
+
+// Route is an entry of the route table registered with Router.Register.
+type Route struct {
+	// Method is the HTTP method of the route, such as http.MethodGet.
+	Method string
+	// Path is the route pattern, such as /users/{id}. See Router.Method for the supported patterns.
+	Path string
+	// Handler is the handler of the route.
+	Handler Handler
+	// Middleware is the middlewares wrapping Handler, chained after the Router's middlewares.
+	Middleware []Middleware
+}
+
+// Register adds every route of routes, so the routes of large services can be defined as data in one place.
+// For example:
+//
+//	err := router.Register([]web.Route{
+//		{Method: http.MethodGet, Path: "/users/{id}", Handler: h.getUser},
+//		{Method: http.MethodPost, Path: "/users", Handler: h.createUser, Middleware: []web.Middleware{auth}},
+//	})
+//
+// It returns an error listing every invalid route, such as a route without handler, with an unknown method
+// or with a method and path combination defined twice, either in routes or already registered in the Router, in which case none
+// of the routes is added.
+func (r *Router) Register(routes []Route) error {
+	registered := make(map[string]struct{})
+	for _, route := range r.Routes() {
+		registered[route.Method+" "+route.Pattern] = struct{}{}
+	}
+
+	var errs []string
+	for i, route := range routes {
+		switch {
+		case route.Method == "":
+			errs = append(errs, fmt.Sprintf("route %d %s has no method", i, route.Path))
+			continue
+		case !isRouteMethod(route.Method):
+			errs = append(errs, fmt.Sprintf("route %d %s %s has an unknown method", i, route.Method, route.Path))
+			continue
+		case !strings.HasPrefix(route.Path, "/"):
+			errs = append(errs, fmt.Sprintf("route %d %s %s must start with /", i, route.Method, route.Path))
+			continue
+		case route.Handler == nil:
+			errs = append(errs, fmt.Sprintf("route %d %s %s has no handler", i, route.Method, route.Path))
+			continue
+		}
+
+		muxPattern, _ := wildcardPattern(route.Path)
+		key := strings.ToUpper(route.Method) + " " + muxPattern
+		if _, ok := registered[key]; ok {
+			errs = append(errs, fmt.Sprintf("duplicated route %s %s", strings.ToUpper(route.Method), route.Path))
+			continue
+		}
+		registered[key] = struct{}{}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid routes: %s", strings.Join(errs, ", "))
+	}
+
+	for _, route := range routes {
+		r.Method(strings.ToUpper(route.Method), route.Path, route.Handler, route.Middleware...)
+	}
+
+	return nil
+}
+
+// isRouteMethod reports whether method, in any case, is one of the methods accepted by Register.
+func isRouteMethod(method string) bool {
+	_, ok := _routeMethods[strings.ToUpper(method)]
+	return ok
+}
@@ -0,0 +1,64 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Register(t *testing.T) {
+	var calls []string
+	tag := func(name string) Middleware {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next(w, r)
+			}
+		}
+	}
+
+	r := New()
+	err := r.Register([]Route{
+		{Method: http.MethodGet, Path: "/users/{id}", Handler: noopHandler},
+		{Method: "post", Path: "/users", Handler: noopHandler, Middleware: []Middleware{tag("auth")}},
+		{Method: http.MethodGet, Path: "/files/*filepath", Handler: noopHandler},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/files/*"},
+		{Method: http.MethodPost, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+	}, r.Routes())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []string{"auth"}, calls)
+}
+
+func TestRouter_RegisterInvalid(t *testing.T) {
+	r := New()
+	r.Get("/ping", noopHandler)
+
+	err := r.Register([]Route{
+		{Method: http.MethodGet, Path: "/users/{id}", Handler: noopHandler},
+		{Method: http.MethodPost, Path: "/users", Handler: noopHandler},
+		{Method: http.MethodGet, Path: "/users/{id}", Handler: noopHandler},
+		{Method: "get", Path: "/ping", Handler: noopHandler},
+		{Method: http.MethodGet, Path: "/files/*filepath", Handler: noopHandler},
+		{Method: http.MethodGet, Path: "/files/*path", Handler: noopHandler},
+		{Method: http.MethodGet, Path: "/health"},
+		{Path: "/status", Handler: noopHandler},
+		{Method: http.MethodGet, Path: "status", Handler: noopHandler},
+		{Method: "FETCH", Path: "/users", Handler: noopHandler},
+	})
+	require.EqualError(t, err, "invalid routes: duplicated route GET /users/{id}, duplicated route GET /ping, "+
+		"duplicated route GET /files/*path, route 6 GET /health has no handler, route 7 /status has no method, "+
+		"route 8 GET status must start with /, route 9 FETCH /users has an unknown method")
+
+	// None of the routes is added.
+	require.Equal(t, []RouteInfo{{Method: http.MethodGet, Pattern: "/ping"}}, r.Routes())
+}
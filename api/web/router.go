@@ -3,9 +3,10 @@ package web
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -112,6 +113,7 @@ type Router struct {
 	mw         []Middleware
 	errEncoder ErrorEncoder
 	errHandler ErrorHandler
+	logErrors  bool
 }
 
 // New instantiates a Router.
@@ -123,6 +125,7 @@ func New() *Router {
 		mux:        mux,
 		errEncoder: DefaultErrorEncoder,
 		errHandler: DefaultErrorHandler,
+		logErrors:  true,
 	}
 }
 
@@ -147,6 +150,13 @@ func (r *Router) ErrorHandler(fn ErrorHandler) {
 	r.errHandler = fn
 }
 
+// LogErrors enables or disables the logging of the errors returned by the handlers, which is enabled by default.
+// The errors are logged with the request logger stored by RequestLogger, carrying the route and request ID,
+// at Warning level for client errors (4xx) and at Error level otherwise.
+func (r *Router) LogErrors(enabled bool) {
+	r.logErrors = enabled
+}
+
 // Group creates a new RouteGroup with the given p prefix and middlewares which are
 // chained after this Router's middlewares.
 func (r *Router) Group(p string, mw ...Middleware) *RouteGroup {
@@ -159,13 +169,27 @@ func (r *Router) Group(p string, mw ...Middleware) *RouteGroup {
 
 // Method adds the route pattern that matches method http method to
 // execute the handler http.Handler wrapped by mw.
+// The pattern may end with a named wildcard such as /files/*filepath, which matches any subpath.
+// The matched subpath is available through Param, and more specific routes take precedence over it.
 func (r *Router) Method(method, pattern string, handler Handler, mw ...Middleware) {
-	r.mux.Method(method, pattern, r.handle(handler, mw...))
+	muxPattern, wildcard := wildcardPattern(pattern)
+	r.mux.Method(method, muxPattern, withRoutePattern(withWildcard(r.handle(handler, mw...), wildcard), pattern))
 }
 
 // Any adds the route pattern that matches any http method to execute the handler http.Handler wrapped by mw.
+// The pattern may end with a named wildcard, see Method.
 func (r *Router) Any(pattern string, handler Handler, mw ...Middleware) {
-	r.mux.Handle(pattern, r.handle(handler, mw...))
+	muxPattern, wildcard := wildcardPattern(pattern)
+	r.mux.Handle(muxPattern, withRoutePattern(withWildcard(r.handle(handler, mw...), wildcard), pattern))
+}
+
+// Mount delegates every request under prefix to h, stripping the prefix from the request path,
+// so h receives /metrics for a request to /admin/metrics when mounted at /admin/.
+// It is meant to embed third-party handlers, such as metrics or documentation UIs, that don't follow
+// the Handler signature. The Router's middlewares are applied to h.
+func (r *Router) Mount(prefix string, h http.Handler) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	r.mux.Handle(prefix+"/*", withRoutePattern(wrapMiddleware(http.StripPrefix(prefix, h).ServeHTTP, r.mw), prefix+"/*"))
 }
 
 func (r *Router) handle(handler Handler, mw ...Middleware) http.Handler {
@@ -175,6 +199,10 @@ func (r *Router) handle(handler Handler, mw ...Middleware) http.Handler {
 			return
 		}
 
+		if r.logErrors {
+			logError(req, err)
+		}
+
 		r.errHandler(req.Context(), err)
 		r.errEncoder(req.Context(), err, w)
 	})
@@ -187,6 +215,63 @@ func (r *Router) handle(handler Handler, mw ...Middleware) http.Handler {
 	return h
 }
 
+type contextKey int
+
+const (
+	wildcardKey contextKey = iota
+	loggerKey
+	routePatternKey
+	apiVersionKey
+	closersKey
+	claimsKey
+)
+
+// RoutePattern returns the template of the route that matched the request as it was registered,
+// such as /users/{id}, rather than the concrete path. It is meant for low cardinality metric labels
+// and access logs. It returns an empty string if no route matched the request.
+func RoutePattern(r *http.Request) string {
+	pattern, _ := r.Context().Value(routePatternKey).(string)
+	return pattern
+}
+
+// withRoutePattern stores the template of the matched route in the request context so RoutePattern can return it.
+func withRoutePattern(h http.Handler, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), routePatternKey, pattern)))
+	})
+}
+
+// Param returns the value of the URL parameter key of the matched route, or an empty string if there is none.
+// For a route ending with a named wildcard such as /files/*filepath, Param(r, "filepath") returns the
+// matched subpath without the leading slash.
+func Param(r *http.Request, key string) string {
+	if wildcard, ok := r.Context().Value(wildcardKey).(string); ok && wildcard == key {
+		return chi.URLParam(r, "*")
+	}
+	return chi.URLParam(r, key)
+}
+
+// wildcardPattern rewrites a pattern ending with a named wildcard, such as /files/*filepath, into the
+// catch-all pattern /files/* and returns it along with the name of the wildcard.
+func wildcardPattern(pattern string) (string, string) {
+	i := strings.LastIndex(pattern, "/*")
+	if i < 0 || strings.Contains(pattern[i+2:], "/") {
+		return pattern, ""
+	}
+	return pattern[:i+2], pattern[i+2:]
+}
+
+// withWildcard stores the name of the route wildcard in the request context so Param can resolve it.
+func withWildcard(h http.Handler, wildcard string) http.Handler {
+	if wildcard == "" {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), wildcardKey, wildcard)))
+	})
+}
+
 // Get is a shortcut for r.Method(http.MethodGet, pattern, handle, mw).
 func (r *Router) Get(pattern string, handler Handler, mw ...Middleware) {
 	r.Method(http.MethodGet, pattern, handler, mw...)
@@ -232,32 +317,35 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.mux.ServeHTTP(w, req)
 }
 
-// Route describes the details of a routing handler.
-type Route struct {
-	Method      string
-	Route       string
-	Handler     http.Handler
-	Middlewares []func(http.Handler) http.Handler
+// RouteInfo describes a route registered in the Router.
+type RouteInfo struct {
+	Method  string
+	Pattern string
 }
 
-// Routes returns the routing tree in an easily traversable structure.
-func (r *Router) Routes() ([]Route, error) {
-	var routes []Route
-	walkFunc := func(method string, route string, handler http.Handler, mw ...func(http.Handler) http.Handler) error {
-		routes = append(routes, Route{
-			Method:      method,
-			Route:       route,
-			Handler:     handler,
-			Middlewares: mw,
+// Routes returns every route registered in the Router, including the ones added
+// through a RouteGroup, sorted by pattern and method.
+func (r *Router) Routes() []RouteInfo {
+	var routes []RouteInfo
+	walkFunc := func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, RouteInfo{
+			Method:  method,
+			Pattern: route,
 		})
 		return nil
 	}
 
-	if err := chi.Walk(r.mux, walkFunc); err != nil {
-		return nil, fmt.Errorf("generating routes: %v", err)
-	}
+	// walkFunc never fails, so neither does chi.Walk.
+	_ = chi.Walk(r.mux, walkFunc)
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		return routes[i].Method < routes[j].Method
+	})
 
-	return routes, nil
+	return routes
 }
 
 // RouteGroup represents a group of routes that share the same path prefix and middlewares.
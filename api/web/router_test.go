@@ -0,0 +1,217 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noopHandler(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func TestRouter_Routes(t *testing.T) {
+	r := New()
+	r.Get("/ping", noopHandler)
+	r.Post("/users", noopHandler)
+	r.Get("/users/{id}", noopHandler)
+
+	g := r.Group("/v1")
+	g.Put("/items/{id}", noopHandler)
+	g.Group("/admin").Delete("/cache", noopHandler)
+
+	require.Equal(t, []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/ping"},
+		{Method: http.MethodPost, Pattern: "/users"},
+		{Method: http.MethodGet, Pattern: "/users/{id}"},
+		{Method: http.MethodDelete, Pattern: "/v1/admin/cache"},
+		{Method: http.MethodPut, Pattern: "/v1/items/{id}"},
+	}, r.Routes())
+}
+
+func TestRouter_RoutesEmpty(t *testing.T) {
+	require.Empty(t, New().Routes())
+}
+
+func TestRouter_Wildcard(t *testing.T) {
+	r := New()
+	r.Get("/files/static", func(w http.ResponseWriter, r *http.Request) error {
+		return EncodeJSON(w, "static", http.StatusOK)
+	})
+	r.Get("/files/*filepath", func(w http.ResponseWriter, r *http.Request) error {
+		return EncodeJSON(w, "wildcard:"+Param(r, "filepath"), http.StatusOK)
+	})
+	r.Group("/users/{id}").Get("/docs/*", func(w http.ResponseWriter, r *http.Request) error {
+		return EncodeJSON(w, Param(r, "id")+":"+Param(r, "*"), http.StatusOK)
+	})
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "static route takes precedence",
+			path:         "/files/static",
+			expectedCode: http.StatusOK,
+			expectedBody: `"static"`,
+		},
+		{
+			name:         "wildcard matches a single segment",
+			path:         "/files/report.csv",
+			expectedCode: http.StatusOK,
+			expectedBody: `"wildcard:report.csv"`,
+		},
+		{
+			name:         "wildcard matches nested segments",
+			path:         "/files/static/css/site.css",
+			expectedCode: http.StatusOK,
+			expectedBody: `"wildcard:static/css/site.css"`,
+		},
+		{
+			name:         "unnamed wildcard",
+			path:         "/users/42/docs/a/b",
+			expectedCode: http.StatusOK,
+			expectedBody: `"42:a/b"`,
+		},
+		{
+			name:         "prefix without wildcard",
+			path:         "/file",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			if tc.expectedBody != "" {
+				require.Equal(t, tc.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWildcardPattern(t *testing.T) {
+	testCases := []struct {
+		pattern          string
+		expectedPattern  string
+		expectedWildcard string
+	}{
+		{pattern: "/files/*filepath", expectedPattern: "/files/*", expectedWildcard: "filepath"},
+		{pattern: "/files/*", expectedPattern: "/files/*", expectedWildcard: ""},
+		{pattern: "/files/{id}", expectedPattern: "/files/{id}", expectedWildcard: ""},
+		{pattern: "/files/*/meta", expectedPattern: "/files/*/meta", expectedWildcard: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			pattern, wildcard := wildcardPattern(tc.pattern)
+			require.Equal(t, tc.expectedPattern, pattern)
+			require.Equal(t, tc.expectedWildcard, wildcard)
+		})
+	}
+}
+
+func TestRouter_Mount(t *testing.T) {
+	r := New()
+
+	var middlewareCalls int
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) {
+			middlewareCalls++
+			next(w, req)
+		}
+	})
+	r.Mount("/admin/", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("admin:" + req.URL.Path))
+	}))
+	r.Get("/admin-users", noopHandler)
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "subpath",
+			path:         "/admin/metrics",
+			expectedCode: http.StatusOK,
+			expectedBody: "admin:/metrics",
+		},
+		{
+			name:         "nested subpath",
+			path:         "/admin/swagger/index.html",
+			expectedCode: http.StatusOK,
+			expectedBody: "admin:/swagger/index.html",
+		},
+		{
+			name:         "root of the prefix",
+			path:         "/admin/",
+			expectedCode: http.StatusOK,
+			expectedBody: "admin:/",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.expectedCode, w.Code)
+			require.Equal(t, tc.expectedBody, w.Body.String())
+		})
+	}
+
+	require.Equal(t, 3, middlewareCalls)
+}
+
+func TestRoutePattern(t *testing.T) {
+	var pattern, middlewarePattern string
+	record := func(w http.ResponseWriter, r *http.Request) error {
+		pattern = RoutePattern(r)
+		return nil
+	}
+
+	r := New()
+	r.Use(func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			middlewarePattern = RoutePattern(r)
+			next(w, r)
+		}
+	})
+	r.Get("/users/{id}", record)
+	r.Group("/v1/orders/{orderID}").Get("/items/{itemID}", record)
+	r.Get("/files/*filepath", record)
+	r.Any("/any/{id}", record)
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{path: "/users/42", expected: "/users/{id}"},
+		{path: "/v1/orders/7/items/3", expected: "/v1/orders/{orderID}/items/{itemID}"},
+		{path: "/files/css/site.css", expected: "/files/*filepath"},
+		{path: "/any/1", expected: "/any/{id}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			pattern, middlewarePattern = "", ""
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+			require.Equal(t, tc.expected, pattern)
+			require.Equal(t, tc.expected, middlewarePattern, "the pattern must be available to the middlewares")
+		})
+	}
+}
+
+func TestRoutePattern_NoMatch(t *testing.T) {
+	require.Empty(t, RoutePattern(httptest.NewRequest(http.MethodGet, "/users/42", nil)))
+}
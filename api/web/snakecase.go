@@ -0,0 +1,414 @@
+package web
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// _maxMarshalDepth bounds the nesting of the values marshalled by MarshalSnakeCase, as encoding/json does,
+// so a value that references itself fails instead of overflowing the stack.
+const _maxMarshalDepth = 1000
+
+// snakeCaseFields caches the fields of the struct types marshalled by MarshalSnakeCase.
+var snakeCaseFields sync.Map //nolint:gochecknoglobals
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()         //nolint:gochecknoglobals
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem() //nolint:gochecknoglobals
+)
+
+// MarshalSnakeCase works like json.Marshal, but the struct fields without a name in their json tag are
+// named after the snake_case form of the Go field name, e.g. CreatedAt becomes created_at and UserID
+// becomes user_id, instead of the Go field name. Fields with an explicit name keep it, and the omitempty,
+// string and "-" tag options are honored. Map keys and the values implementing json.Marshaler or
+// encoding.TextMarshaler, such as time.Time, are marshalled as is. It can be used as JSONMarshal.
+func MarshalSnakeCase(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeSnakeCase(&buf, reflect.ValueOf(v), 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSnakeCase(buf *bytes.Buffer, v reflect.Value, depth int) error {
+	if depth > _maxMarshalDepth {
+		return errors.New("json: unsupported value: encountered a cycle")
+	}
+
+	if !v.IsValid() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) ||
+		(v.CanAddr() && (reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) ||
+			reflect.PointerTo(v.Type()).Implements(textMarshalerType))) {
+		return writeMarshalled(buf, v)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeSnakeCase(buf, v.Elem(), depth+1)
+	case reflect.Struct:
+		return writeStructSnakeCase(buf, v, depth)
+	case reflect.Map:
+		return writeMapSnakeCase(buf, v, depth)
+	case reflect.Slice:
+		if v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return writeMarshalled(buf, v)
+		}
+		fallthrough
+	case reflect.Array:
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeSnakeCase(buf, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	default:
+		return writeMarshalled(buf, v)
+	}
+}
+
+// writeMarshalled writes v marshalled by encoding/json.
+func writeMarshalled(buf *bytes.Buffer, v reflect.Value) error {
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+
+	return nil
+}
+
+func writeStructSnakeCase(buf *bytes.Buffer, v reflect.Value, depth int) error {
+	buf.WriteByte('{')
+
+	first := true
+	for _, f := range structSnakeCaseFields(v.Type()) {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		buf.Write(f.key)
+		buf.WriteByte(':')
+
+		if !f.quoted {
+			if err := writeSnakeCase(buf, fv, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The string option encodes the value as a JSON string, as encoding/json does.
+		var value bytes.Buffer
+		if err := writeSnakeCase(&value, fv, depth+1); err != nil {
+			return err
+		}
+		quoted, err := json.Marshal(value.String())
+		if err != nil {
+			return err
+		}
+		buf.Write(quoted)
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+// fieldByIndex returns the field of v at index, reporting false when it is promoted through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func writeMapSnakeCase(buf *bytes.Buffer, v reflect.Value, depth int) error {
+	if v.IsNil() {
+		buf.WriteString("null")
+		return nil
+	}
+
+	type entry struct {
+		key   string
+		value reflect.Value
+	}
+
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{key: key, value: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	buf.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(e.key)
+		if err != nil {
+			return err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		if err := writeSnakeCase(buf, e.value, depth+1); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// mapKey returns the JSON object key of the map key k, following the rules of encoding/json.
+func mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		return string(text), err
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("json: unsupported type: %s", k.Type())
+	}
+}
+
+// snakeCaseField is a field of a struct as marshalled by MarshalSnakeCase.
+type snakeCaseField struct {
+	name      string
+	key       []byte // name encoded as a JSON string
+	index     []int
+	tagged    bool
+	omitEmpty bool
+	quoted    bool
+}
+
+// structSnakeCaseFields returns the fields of t to marshal in order, including the fields promoted from
+// embedded structs, resolving the name conflicts like encoding/json does.
+func structSnakeCaseFields(t reflect.Type) []snakeCaseField {
+	if fields, ok := snakeCaseFields.Load(t); ok {
+		return fields.([]snakeCaseField)
+	}
+
+	var candidates []snakeCaseField
+	collectSnakeCaseFields(t, nil, map[reflect.Type]bool{}, &candidates)
+
+	// Among the fields with the same name, the shallowest one wins, and when there are several
+	// at the same depth, the only tagged one. Otherwise, all of them are dropped.
+	byName := make(map[string][]snakeCaseField)
+	for _, f := range candidates {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var fields []snakeCaseField
+	for _, f := range candidates {
+		if dominant, ok := dominantField(byName[f.name]); ok && sameIndex(dominant.index, f.index) {
+			f.key, _ = json.Marshal(f.name)
+			fields = append(fields, f)
+		}
+	}
+
+	snakeCaseFields.Store(t, fields)
+	return fields
+}
+
+func collectSnakeCaseFields(t reflect.Type, index []int, visited map[reflect.Type]bool, fields *[]snakeCaseField) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous {
+			if !sf.IsExported() && ft.Kind() != reflect.Struct {
+				continue
+			}
+			// Untagged embedded structs have their fields promoted.
+			if name == "" && ft.Kind() == reflect.Struct {
+				collectSnakeCaseFields(ft, append(append([]int(nil), index...), i), visited, fields)
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		f := snakeCaseField{
+			name:      name,
+			index:     append(append([]int(nil), index...), i),
+			tagged:    name != "",
+			omitEmpty: hasTagOption(opts, "omitempty"),
+		}
+		if f.name == "" {
+			f.name = snakeCase(sf.Name)
+		}
+
+		if hasTagOption(opts, "string") {
+			switch ft.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64, reflect.String:
+				f.quoted = true
+			}
+		}
+
+		*fields = append(*fields, f)
+	}
+}
+
+func dominantField(fields []snakeCaseField) (snakeCaseField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+
+	var dominant []snakeCaseField
+	for _, f := range fields {
+		if len(f.index) == depth {
+			dominant = append(dominant, f)
+		}
+	}
+	if len(dominant) == 1 {
+		return dominant[0], true
+	}
+
+	var tagged []snakeCaseField
+	for _, f := range dominant {
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	if len(tagged) == 1 {
+		return tagged[0], true
+	}
+
+	return snakeCaseField{}, false
+}
+
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTagOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyValue reports whether v is empty according to the omitempty option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping acronyms together,
+// e.g. UserID becomes user_id and HTTPServer becomes http_server.
+func snakeCase(s string) string {
+	runes := []rune(s)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
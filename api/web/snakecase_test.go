@@ -0,0 +1,123 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalSnakeCase(t *testing.T) {
+	type Audit struct {
+		CreatedAt time.Time
+		CreatedBy string `json:"author"`
+	}
+	type Address struct {
+		StreetName string
+		ZipCode    string `json:"zip,omitempty"`
+	}
+	type user struct {
+		UserID      int
+		FirstName   string
+		HTTPReferer string
+		Nickname    string `json:"nick"`
+		Email       *string
+		Password    string `json:"-"`
+		Age         int    `json:",omitempty"`
+		Score       int    `json:",string"`
+		Address     Address
+		Tags        []string
+		Metadata    map[string]interface{}
+		internal    string
+		Audit
+	}
+
+	createdAt := time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{
+			name: "fields with and without tags",
+			value: user{
+				UserID:      42,
+				FirstName:   "John",
+				HTTPReferer: "https://example.com",
+				Nickname:    "<jd>",
+				Password:    "secret",
+				Score:       7,
+				Address:     Address{StreetName: "Main"},
+				Tags:        []string{"admin"},
+				Metadata:    map[string]interface{}{"LastLogin": nil, "Nested": Address{ZipCode: "050001"}},
+				internal:    "internal",
+				Audit:       Audit{CreatedAt: createdAt, CreatedBy: "admin"},
+			},
+			expected: `{"user_id":42,"first_name":"John","http_referer":"https://example.com","nick":"\u003cjd\u003e",` +
+				`"email":null,"score":"7","address":{"street_name":"Main"},"tags":["admin"],` +
+				`"metadata":{"LastLogin":null,"Nested":{"street_name":"","zip":"050001"}},` +
+				`"created_at":"2024-05-12T10:00:00Z","author":"admin"}`,
+		},
+		{
+			name:     "pointer and slice of structs",
+			value:    &[]Address{{StreetName: "Main", ZipCode: "050001"}},
+			expected: `[{"street_name":"Main","zip":"050001"}]`,
+		},
+		{
+			name:     "null",
+			value:    nil,
+			expected: `null`,
+		},
+		{
+			name:     "scalar",
+			value:    "pong",
+			expected: `"pong"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := MarshalSnakeCase(tc.value)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestMarshalSnakeCase_Error(t *testing.T) {
+	_, err := MarshalSnakeCase(map[string]interface{}{"Callback": func() {}})
+	require.EqualError(t, err, "json: unsupported type: func()")
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, expected := range map[string]string{
+		"ID":          "id",
+		"UserID":      "user_id",
+		"CreatedAt":   "created_at",
+		"HTTPServer":  "http_server",
+		"Address2":    "address2",
+		"Address2Zip": "address2_zip",
+		"lowercase":   "lowercase",
+	} {
+		require.Equal(t, expected, snakeCase(name), name)
+	}
+}
+
+func TestEncodeJSON_JSONMarshalSnakeCase(t *testing.T) {
+	JSONMarshal = MarshalSnakeCase
+	t.Cleanup(func() {
+		JSONMarshal = nil
+	})
+
+	type user struct {
+		UserID   int
+		Nickname string `json:"nick"`
+	}
+
+	w := httptest.NewRecorder()
+	err := EncodeJSON(w, user{UserID: 42, Nickname: "jd"}, http.StatusOK)
+	require.NoError(t, err)
+	require.Equal(t, `{"user_id":42,"nick":"jd"}`, w.Body.String())
+}
@@ -0,0 +1,24 @@
+// Package sqllex provides the lexing helpers shared by the packages that scan MySQL queries and scripts.
+package sqllex
+
+// QuoteEnd returns the index right after the quoted string or identifier starting at start.
+// The quote is escaped either by doubling it or, except for identifiers, with a backslash.
+// It returns the length of s when the quote is not closed.
+func QuoteEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,28 @@
+package sqllex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteEnd(t *testing.T) {
+	testCases := []struct {
+		s        string
+		expected int
+	}{
+		{s: `'foo' AND`, expected: 5},
+		{s: `'it''s' AND`, expected: 7},
+		{s: `'it\'s' AND`, expected: 7},
+		{s: `"say ""hi""" AND`, expected: 12},
+		{s: "`a\\` AND", expected: 4},
+		{s: "`a``b` AND", expected: 6},
+		{s: `'not closed`, expected: 11},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			require.Equal(t, tc.expected, QuoteEnd(tc.s, 0))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const _defaultAcquireTimeout = time.Second
+
+// ErrPoolExhausted is returned by a BoundedDB when no connection of the pool becomes available
+// within the acquire timeout of the connection.
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// BoundedDB executes queries on a connection pool waiting at most the acquire timeout of the connection
+// for a free connection, so callers fail fast with ErrPoolExhausted when MaxOpenConnections is saturated
+// instead of blocking until a connection is released. Once acquired, the query is only bound by its context.
+type BoundedDB struct {
+	name           string
+	db             *sql.DB
+	acquireTimeout time.Duration
+}
+
+func newBoundedDB(name string, db *sql.DB, acquireTimeout Duration) *BoundedDB {
+	b := &BoundedDB{
+		name:           name,
+		db:             db,
+		acquireTimeout: _defaultAcquireTimeout,
+	}
+
+	if acquireTimeout > 0 {
+		b.acquireTimeout = time.Duration(acquireTimeout)
+	}
+
+	return b
+}
+
+// ExecContext executes a query without returning any rows.
+func (b *BoundedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	conn, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows. The connection is held until the returned rows are closed.
+func (b *BoundedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*BoundedRows, error) {
+	conn, err := b.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return &BoundedRows{Rows: rows, conn: conn}, nil
+}
+
+// acquire gets a connection from the pool, waiting at most the acquire timeout.
+func (b *BoundedDB) acquire(ctx context.Context) (*sql.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, b.acquireTimeout)
+	defer cancel()
+
+	conn, err := b.db.Conn(acquireCtx)
+	if err != nil {
+		// Only the acquire timeout means that the pool is exhausted, the caller's context is reported as is.
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("connection %q: %w: no connection available within %s",
+				b.name, ErrPoolExhausted, b.acquireTimeout)
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// BoundedRows are the rows returned by BoundedDB.QueryContext.
+// Close must be called to release the connection back to the pool.
+type BoundedRows struct {
+	*sql.Rows
+	conn *sql.Conn
+}
+
+// Close closes the rows and releases the connection back to the pool.
+func (r *BoundedRows) Close() error {
+	err := r.Rows.Close()
+	if closeErr := r.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
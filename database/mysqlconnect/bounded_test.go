@@ -0,0 +1,121 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func givenBoundedConnections(t *testing.T) Connections {
+	t.Helper()
+
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						return driver.RowsAffected(1), nil
+					},
+					QueryFunc: func(args []driver.Value) (driver.Rows, error) {
+						return &mocks.DriverRowsMock{}, nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	maxOpen := 1
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name:           "foo",
+				AcquireTimeout: Duration(50 * time.Millisecond),
+				ConnectionPool: ConnectionPool{MaxOpenConnections: &maxOpen},
+			},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = connections.Close() })
+
+	return connections
+}
+
+func TestBoundedDB_PoolExhausted(t *testing.T) {
+	connections := givenBoundedConnections(t)
+
+	bounded, err := connections.GetBounded("foo")
+	require.NoError(t, err)
+
+	result, err := bounded.ExecContext(context.Background(), "UPDATE foo SET bar = 1")
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+
+	// Hold the only connection of the pool.
+	held, err := connections.Conn(context.Background(), "foo")
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = bounded.ExecContext(context.Background(), "UPDATE foo SET bar = 1")
+	require.ErrorIs(t, err, ErrPoolExhausted)
+	require.EqualError(t, err, `connection "foo": connection pool exhausted: no connection available within 50ms`)
+	require.Less(t, time.Since(start), time.Second)
+
+	_, err = bounded.QueryContext(context.Background(), "SELECT 1")
+	require.ErrorIs(t, err, ErrPoolExhausted)
+
+	// The caller's context is reported as is.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = bounded.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, ErrPoolExhausted)
+
+	require.NoError(t, held.Close())
+
+	_, err = bounded.ExecContext(context.Background(), "UPDATE foo SET bar = 1")
+	require.NoError(t, err)
+}
+
+func TestBoundedDB_QueryContextReleasesConnectionOnClose(t *testing.T) {
+	connections := givenBoundedConnections(t)
+
+	bounded, err := connections.GetBounded("foo")
+	require.NoError(t, err)
+
+	rows, err := bounded.QueryContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	require.False(t, rows.Next())
+
+	// The rows hold the only connection of the pool until they are closed.
+	_, err = bounded.ExecContext(context.Background(), "UPDATE foo SET bar = 1")
+	require.ErrorIs(t, err, ErrPoolExhausted)
+
+	require.NoError(t, rows.Close())
+
+	_, err = bounded.ExecContext(context.Background(), "UPDATE foo SET bar = 1")
+	require.NoError(t, err)
+}
+
+func TestConnections_GetBounded(t *testing.T) {
+	connections := givenBoundedConnections(t)
+
+	_, err := connections.GetBounded("bar")
+	require.EqualError(t, err, "unknown connection name bar")
+
+	connections.Pause()
+	_, err = connections.GetBounded("foo")
+	require.ErrorIs(t, err, ErrPaused)
+}
+
+func TestNewBoundedDB_DefaultAcquireTimeout(t *testing.T) {
+	require.Equal(t, time.Second, newBoundedDB("foo", nil, 0).acquireTimeout)
+	require.Equal(t, 5*time.Second, newBoundedDB("foo", nil, Duration(5*time.Second)).acquireTimeout)
+}
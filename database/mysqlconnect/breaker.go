@@ -0,0 +1,211 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	_defaultBreakerFailureThreshold = 5
+	_defaultBreakerCooldown         = 30 * time.Second
+)
+
+// ErrBreakerOpen is returned by a CircuitBreaker when it fast-fails a query because it is open.
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig is the configuration of the circuit breaker of a connection.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed queries that trips the breaker open.
+	// It defaults to 5.
+	FailureThreshold int `json:"failure_threshold"`
+	// Cooldown is the amount of time the breaker stays open before letting a trial query through.
+	// It defaults to 30s.
+	Cooldown Duration `json:"cooldown"`
+}
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+const (
+	// BreakerClosed means that queries are executed normally.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means that queries fast-fail with ErrBreakerOpen.
+	BreakerOpen
+	// BreakerHalfOpen means that the cooldown has elapsed and a single trial query is let through
+	// to decide whether the breaker closes again or goes back to open.
+	BreakerHalfOpen
+)
+
+// String returns the name of the state.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker executes queries on a connection pool and stops issuing them for a cooldown period
+// after a number of consecutive failures, so an unhealthy database fails fast instead of piling up timeouts.
+// A query cancelled by its context is not considered a failure.
+type CircuitBreaker struct {
+	name             string
+	db               *sql.DB
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+	// generation is incremented every time the breaker opens or closes, so the results of the queries
+	// allowed before the transition are ignored.
+	generation uint64
+}
+
+// breakerTicket identifies a query allowed by a CircuitBreaker, to record its result.
+type breakerTicket struct {
+	generation uint64
+	trial      bool
+}
+
+func newCircuitBreaker(name string, db *sql.DB, config *CircuitBreakerConfig) *CircuitBreaker {
+	b := &CircuitBreaker{
+		name:             name,
+		db:               db,
+		failureThreshold: _defaultBreakerFailureThreshold,
+		cooldown:         _defaultBreakerCooldown,
+		now:              time.Now,
+	}
+
+	if config != nil {
+		if config.FailureThreshold > 0 {
+			b.failureThreshold = config.FailureThreshold
+		}
+		if config.Cooldown > 0 {
+			b.cooldown = time.Duration(config.Cooldown)
+		}
+	}
+
+	return b
+}
+
+// ExecContext executes a query without returning any rows through the breaker.
+func (b *CircuitBreaker) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ticket, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := b.db.ExecContext(ctx, query, args...)
+	b.record(ticket, err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows through the breaker.
+func (b *CircuitBreaker) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ticket, err := b.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	b.record(ticket, err)
+	return rows, err
+}
+
+// State returns the current state of the breaker.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow returns an error if the query must fast-fail, or the ticket to record its result with otherwise.
+func (b *CircuitBreaker) allow() (breakerTicket, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return breakerTicket{}, b.openError()
+		}
+		b.state = BreakerHalfOpen
+	case BreakerHalfOpen:
+		// Only one trial query is let through while half-open.
+		if b.trial {
+			return breakerTicket{}, b.openError()
+		}
+	case BreakerClosed:
+		return breakerTicket{generation: b.generation}, nil
+	}
+
+	b.trial = true
+	return breakerTicket{generation: b.generation, trial: true}, nil
+}
+
+// record updates the state of the breaker with the result of the query allowed with the given ticket.
+// The results of the queries allowed before the breaker last opened or closed are ignored, so a slow query
+// can't close a breaker that tripped while it was running, and only the trial query decides the outcome
+// of the half-open state.
+func (b *CircuitBreaker) record(ticket breakerTicket, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	if errors.Is(err, context.Canceled) {
+		if ticket.trial {
+			b.trial = false
+		}
+		return
+	}
+
+	switch {
+	case err == nil:
+		if ticket.trial {
+			b.transition(BreakerClosed)
+		}
+		b.failures = 0
+	case ticket.trial:
+		b.transition(BreakerOpen)
+	default:
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.transition(BreakerOpen)
+		}
+	}
+}
+
+// transition opens or closes the breaker, starting a new generation.
+func (b *CircuitBreaker) transition(state BreakerState) {
+	b.state = state
+	b.failures = 0
+	b.trial = false
+	b.generation++
+	if state == BreakerOpen {
+		b.openedAt = b.now()
+	}
+}
+
+func (b *CircuitBreaker) openError() error {
+	return fmt.Errorf("connection %q: %w", b.name, ErrBreakerOpen)
+}
@@ -0,0 +1,241 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing bool
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				if failing {
+					return nil, errors.New("i/o timeout")
+				}
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						return driver.RowsAffected(1), nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error {
+				return nil
+			},
+		}, nil
+	}
+
+	config := Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name: "replica",
+				CircuitBreaker: &CircuitBreakerConfig{
+					FailureThreshold: 2,
+					Cooldown:         Duration(time.Minute),
+				},
+			},
+		},
+	}
+
+	connections, err := Open(config)
+	require.NoError(t, err)
+
+	breaker, err := connections.GetWithBreaker("replica")
+	require.NoError(t, err)
+
+	now := time.Now()
+	breaker.now = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.NoError(t, err)
+	require.Equal(t, BreakerClosed, breaker.State())
+
+	// Trip the breaker after two consecutive failures.
+	failing = true
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.EqualError(t, err, "i/o timeout")
+	require.Equal(t, BreakerClosed, breaker.State())
+
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.EqualError(t, err, "i/o timeout")
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// While open, queries fast-fail even if the database recovered.
+	failing = false
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.ErrorIs(t, err, ErrBreakerOpen)
+	require.EqualError(t, err, "connection \"replica\": circuit breaker is open")
+
+	// After the cooldown a failed trial query opens the breaker again.
+	now = now.Add(time.Minute)
+	require.Equal(t, BreakerHalfOpen, breaker.State())
+
+	failing = true
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.EqualError(t, err, "i/o timeout")
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	// After the cooldown a successful trial query closes the breaker.
+	now = now.Add(time.Minute)
+	failing = false
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.NoError(t, err)
+	require.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenLetsOneTrialThrough(t *testing.T) {
+	b := newCircuitBreaker("replica", nil, &CircuitBreakerConfig{FailureThreshold: 1, Cooldown: Duration(time.Second)})
+
+	now := time.Now()
+	b.now = func() time.Time {
+		return now
+	}
+
+	ticket, err := b.allow()
+	require.NoError(t, err)
+	b.record(ticket, errors.New("i/o timeout"))
+	require.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(time.Second)
+	trial, err := b.allow()
+	require.NoError(t, err)
+	_, err = b.allow()
+	require.ErrorIs(t, err, ErrBreakerOpen)
+
+	b.record(trial, nil)
+	require.Equal(t, BreakerClosed, b.State())
+	_, err = b.allow()
+	require.NoError(t, err)
+}
+
+func TestCircuitBreaker_IgnoresStaleResults(t *testing.T) {
+	b := newCircuitBreaker("replica", nil, &CircuitBreakerConfig{FailureThreshold: 1, Cooldown: Duration(time.Second)})
+
+	now := time.Now()
+	b.now = func() time.Time {
+		return now
+	}
+
+	slowSuccess, err := b.allow()
+	require.NoError(t, err)
+	slowFailure, err := b.allow()
+	require.NoError(t, err)
+	failure, err := b.allow()
+	require.NoError(t, err)
+
+	b.record(failure, errors.New("i/o timeout"))
+	require.Equal(t, BreakerOpen, b.State())
+
+	// The queries allowed before the breaker tripped neither close it nor delay its cooldown.
+	b.record(slowSuccess, nil)
+	require.Equal(t, BreakerOpen, b.State())
+
+	now = now.Add(500 * time.Millisecond)
+	b.record(slowFailure, errors.New("i/o timeout"))
+	now = now.Add(500 * time.Millisecond)
+	require.Equal(t, BreakerHalfOpen, b.State())
+
+	// Only the trial query decides the outcome of the half-open state.
+	trial, err := b.allow()
+	require.NoError(t, err)
+	b.record(slowSuccess, nil)
+	_, err = b.allow()
+	require.ErrorIs(t, err, ErrBreakerOpen, "a stale result must not let a second trial through")
+
+	b.record(trial, nil)
+	require.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_SlowSuccessDoesNotCloseTrippedBreaker(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				if query != "SELECT SLEEP(1)" {
+					return nil, errors.New("i/o timeout")
+				}
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						close(started)
+						<-release
+						return driver.RowsAffected(0), nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{Name: "replica", CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 1, Cooldown: Duration(time.Minute)}},
+		},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	breaker, err := connections.GetWithBreaker("replica")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	done := make(chan error, 1)
+	go func() {
+		_, err := breaker.ExecContext(ctx, "SELECT SLEEP(1)")
+		done <- err
+	}()
+	<-started
+
+	_, err = breaker.ExecContext(ctx, "UPDATE foo SET bar = 1")
+	require.EqualError(t, err, "i/o timeout")
+	require.Equal(t, BreakerOpen, breaker.State())
+
+	close(release)
+	require.NoError(t, <-done)
+	require.Equal(t, BreakerOpen, breaker.State(), "a slow success must not undo the trip")
+}
+
+func TestCircuitBreaker_CanceledQueriesAreNotFailures(t *testing.T) {
+	b := newCircuitBreaker("replica", nil, &CircuitBreakerConfig{FailureThreshold: 1})
+
+	ticket, err := b.allow()
+	require.NoError(t, err)
+	b.record(ticket, context.Canceled)
+	require.Equal(t, BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_Defaults(t *testing.T) {
+	b := newCircuitBreaker("replica", nil, nil)
+	require.Equal(t, 5, b.failureThreshold)
+	require.Equal(t, 30*time.Second, b.cooldown)
+}
+
+func TestConnections_GetWithBreakerUnknownConnection(t *testing.T) {
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
+	require.NoError(t, err)
+
+	_, err = connections.GetWithBreaker("bar")
+	require.EqualError(t, err, "unknown connection name bar")
+}
+
+func TestBreakerState_String(t *testing.T) {
+	require.Equal(t, "closed", BreakerClosed.String())
+	require.Equal(t, "open", BreakerOpen.String())
+	require.Equal(t, "half-open", BreakerHalfOpen.String())
+	require.Equal(t, "unknown", BreakerState(42).String())
+}
@@ -0,0 +1,78 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// dsnConnector is the driver.Connector of a driver that doesn't implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// initConnector is a driver.Connector that executes the init statements on every new connection.
+type initConnector struct {
+	driver.Connector
+	statements []string
+}
+
+func (c *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range c.statements {
+		if err := execStatement(ctx, conn, statement); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to execute init statement %q: %w", statement, err)
+		}
+	}
+
+	return conn, nil
+}
+
+// Close closes the wrapped connector if it holds any resources, since sql.DB.Close only closes
+// the connectors that implement io.Closer.
+func (c *initConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// execStatement executes statement on conn, preparing it when the connection can't execute it directly.
+func execStatement(ctx context.Context, conn driver.Conn, statement string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, statement, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+
+	stmt, err := conn.Prepare(statement)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if s, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = s.ExecContext(ctx, nil)
+		return err
+	}
+
+	_, err = stmt.Exec(nil) //nolint:staticcheck
+	return err
+}
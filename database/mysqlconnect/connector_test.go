@@ -0,0 +1,195 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_InitStatements(t *testing.T) {
+	var executed []string
+	var closed int
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						if query == "SET SESSION unknown_variable = 1" {
+							return nil, errors.New("unknown system variable")
+						}
+						executed = append(executed, query)
+						return driver.RowsAffected(0), nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error {
+				closed++
+				return nil
+			},
+		}, nil
+	}
+
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name: "foo",
+				InitStatements: []string{
+					"SET SESSION sql_mode = 'STRICT_ALL_TABLES'",
+					"SET time_zone = '+00:00'",
+				},
+			},
+			{
+				Name:           "bar",
+				InitStatements: []string{"SET SESSION unknown_variable = 1"},
+			},
+			{
+				Name: "baz",
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	foo, err := connections.Get("foo")
+	require.NoError(t, err)
+	require.NoError(t, foo.Ping())
+	require.Equal(t, []string{"SET SESSION sql_mode = 'STRICT_ALL_TABLES'", "SET time_zone = '+00:00'"}, executed)
+
+	// The statements only run when a new connection is opened, not when an idle one is reused.
+	require.NoError(t, foo.Ping())
+	require.Len(t, executed, 2)
+
+	bar, err := connections.Get("bar")
+	require.NoError(t, err)
+	err = bar.Ping()
+	require.EqualError(t, err, `failed to execute init statement "SET SESSION unknown_variable = 1": unknown system variable`)
+	require.Equal(t, 1, closed, "the connection must be discarded when an init statement fails")
+
+	baz, err := connections.Get("baz")
+	require.NoError(t, err)
+	require.NoError(t, baz.Ping())
+	require.Len(t, executed, 2)
+}
+
+func TestOpen_InitStatementsCloseConnector(t *testing.T) {
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/close_with_error",
+		Connections: []Connection{
+			{
+				Name:           "foo",
+				InitStatements: []string{"SET time_zone = '+00:00'"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.EqualError(t, connections.Close(), "failed to close connections: foo: driver: bad connection")
+}
+
+// recordingDriver is a driver.Driver wrapper that records the connections it opens.
+type recordingDriver struct {
+	driver.Driver
+	label  string
+	opened *[]string
+}
+
+func (d recordingDriver) Open(name string) (driver.Conn, error) {
+	*d.opened = append(*d.opened, d.label)
+	return d.Driver.Open(name)
+}
+
+func TestOpen_WithDriverWrapper(t *testing.T) {
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{CloseFunc: func() error { return nil }}, nil
+	}
+
+	var opened []string
+	wrapper := func(label string) DriverWrapper {
+		return func(name string, d driver.Driver) driver.Driver {
+			return recordingDriver{Driver: d, label: label + ":" + name, opened: &opened}
+		}
+	}
+
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}, {Name: "bar"}},
+	}, WithDriverWrapper(wrapper("inner")), WithDriverWrapper(wrapper("outer")))
+	require.NoError(t, err)
+	defer connections.Close()
+
+	foo, err := connections.Get("foo")
+	require.NoError(t, err)
+	require.NoError(t, foo.Ping())
+	require.Equal(t, []string{"outer:foo", "inner:foo"}, opened)
+
+	bar, err := connections.Get("bar")
+	require.NoError(t, err)
+	require.NoError(t, bar.Ping())
+	require.Equal(t, []string{"outer:foo", "inner:foo", "outer:bar", "inner:bar"}, opened)
+}
+
+// closingDriver is a driver.DriverContext wrapper whose connectors record the name of their pool when
+// it is closed. It fails to open the connector when fail is set.
+type closingDriver struct {
+	driver.Driver
+	name   string
+	fail   bool
+	closed *[]string
+}
+
+func (d closingDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	if d.fail {
+		return nil, errors.New("cannot open connector")
+	}
+	return closingConnector{driver: d.Driver, dsn: dsn, name: d.name, closed: d.closed}, nil
+}
+
+type closingConnector struct {
+	driver driver.Driver
+	dsn    string
+	name   string
+	closed *[]string
+}
+
+func (c closingConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c closingConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c closingConnector) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return nil
+}
+
+func TestOpen_ClosesOpenedPoolsOnError(t *testing.T) {
+	var wrapped, closed []string
+	wrapper := func(name string, d driver.Driver) driver.Driver {
+		wrapped = append(wrapped, name)
+		return closingDriver{Driver: d, name: name, fail: name == "bar", closed: &closed}
+	}
+
+	_, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}, {Name: "bar"}},
+	}, WithDriverWrapper(wrapper))
+	require.EqualError(t, err, "cannot open connector")
+	require.Equal(t, []string{"foo", "bar"}, wrapped)
+	require.Equal(t, []string{"foo"}, closed, "the pools opened before the failed one must be closed")
+
+	// The invalid connections are rejected before opening any pool.
+	wrapped, closed = nil, nil
+	_, err = Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}, {Name: "baz", Weight: -1}},
+	}, WithDriverWrapper(wrapper))
+	require.EqualError(t, err, `invalid MySQL config: weight must not be negative: connection "baz"`)
+	require.Empty(t, wrapped)
+}
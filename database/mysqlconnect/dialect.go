@@ -0,0 +1,133 @@
+package mysqlconnect
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialect translates the engine agnostic settings of a Connection, such as TLS or the timeouts, to the DSN
+// parameters of a database engine, so the configuration stays the same regardless of the engine.
+type dialect interface {
+	// parameters returns the DSN parameters for the engine agnostic settings of c, in the form key=value.
+	parameters(c Connection) []string
+}
+
+// mysqlDialect translates the settings to the parameters of the github.com/go-sql-driver/mysql driver.
+type mysqlDialect struct{}
+
+var _ dialect = mysqlDialect{}
+
+func (mysqlDialect) parameters(c Connection) []string {
+	var parameters []string
+	if c.TLS {
+		parameters = append(parameters, "tls=true")
+	}
+	if c.ParseTime != nil {
+		parameters = append(parameters, "parseTime="+strconv.FormatBool(*c.ParseTime))
+	}
+	if c.ConnectTimeout > 0 {
+		parameters = append(parameters, "timeout="+time.Duration(c.ConnectTimeout).String())
+	}
+	if c.ReadTimeout > 0 {
+		parameters = append(parameters, "readTimeout="+time.Duration(c.ReadTimeout).String())
+	}
+	if c.WriteTimeout > 0 {
+		parameters = append(parameters, "writeTimeout="+time.Duration(c.WriteTimeout).String())
+	}
+	if c.DefaultIsolation != "" {
+		// The driver sets the unknown parameters as session variables, whose string values must be quoted.
+		parameters = append(parameters, "transaction_isolation="+url.QueryEscape("'"+c.DefaultIsolation+"'"))
+	}
+	if c.InterpolateParams != nil {
+		parameters = append(parameters, "interpolateParams="+strconv.FormatBool(*c.InterpolateParams))
+	}
+	return parameters
+}
+
+// _isolationLevels are the transaction isolation levels supported by Connection.DefaultIsolation.
+var _isolationLevels = []string{"READ-UNCOMMITTED", "READ-COMMITTED", "REPEATABLE-READ", "SERIALIZABLE"} //nolint:gochecknoglobals
+
+// isIsolationLevel reports whether level is one of the transaction isolation levels of MySQL.
+func isIsolationLevel(level string) bool {
+	for _, l := range _isolationLevels {
+		if level == l {
+			return true
+		}
+	}
+	return false
+}
+
+// _unsafeInterpolationCharsets are the multibyte charsets whose escaping by the driver with interpolateParams
+// can be bypassed, since some of their characters end with a byte that is a backslash or a quote in ASCII.
+var _unsafeInterpolationCharsets = []string{"big5", "cp932", "gb2312", "gb18030", "gbk", "sjis"} //nolint:gochecknoglobals
+
+// unsafeInterpolationCharset returns the charset of c that is unsafe with interpolateParams, taken from the
+// charset and collation parameters, when c enables InterpolateParams.
+func unsafeInterpolationCharset(c Connection) (string, bool) {
+	if c.InterpolateParams == nil || !*c.InterpolateParams {
+		return "", false
+	}
+
+	// The driver tries the charsets of the comma separated list in order, and a collation starts with the
+	// name of its charset, e.g. gbk_bin.
+	charsets := strings.Split(c.parameter("charset"), ",")
+	if collation, _, _ := strings.Cut(c.parameter("collation"), "_"); collation != "" {
+		charsets = append(charsets, collation)
+	}
+
+	for _, charset := range charsets {
+		charset = strings.ToLower(strings.TrimSpace(charset))
+		for _, unsafe := range _unsafeInterpolationCharsets {
+			if charset == unsafe {
+				return charset, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parameter returns the value of the parameter with the given key, set in either Parameters or ParametersMap.
+func (c Connection) parameter(key string) string {
+	if len(c.ParametersMap) > 0 {
+		return c.ParametersMap[key]
+	}
+
+	// The driver unescapes the values of the parameters like a query string.
+	values, _ := url.ParseQuery(c.Parameters)
+	return values.Get(key)
+}
+
+// withParameters appends to parameters the extra ones whose key is not already set, so the parameters
+// explicitly defined take precedence.
+func withParameters(parameters string, extra []string) string {
+	var result []string
+	set := make(map[string]struct{})
+	if parameters != "" {
+		result = append(result, parameters)
+		for _, parameter := range strings.Split(parameters, "&") {
+			key, _, _ := strings.Cut(parameter, "=")
+			set[key] = struct{}{}
+		}
+	}
+
+	for _, parameter := range extra {
+		key, _, _ := strings.Cut(parameter, "=")
+		if _, ok := set[key]; !ok {
+			result = append(result, parameter)
+		}
+	}
+
+	return strings.Join(result, "&")
+}
+
+// encodeParameters returns parameters in the form key=value joined by &, sorted by key and query escaped,
+// which the driver unescapes when parsing the DSN.
+func encodeParameters(parameters map[string]string) string {
+	values := make(url.Values, len(parameters))
+	for key, value := range parameters {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
@@ -0,0 +1,149 @@
+package mysqlconnect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLDialect(t *testing.T) {
+	parseTime, interpolateParams, noInterpolateParams := false, true, false
+
+	testCases := []struct {
+		name       string
+		connection Connection
+		expected   string
+	}{
+		{
+			name:       "no settings",
+			connection: Connection{},
+			expected:   "bar_WPROD:secret@tcp(master.local:3306)/bar?parseTime=true",
+		},
+		{
+			name: "every setting",
+			connection: Connection{
+				TLS:               true,
+				ParseTime:         &parseTime,
+				ConnectTimeout:    Duration(time.Second),
+				ReadTimeout:       Duration(100 * time.Millisecond),
+				WriteTimeout:      Duration(200 * time.Millisecond),
+				DefaultIsolation:  "READ-COMMITTED",
+				InterpolateParams: &interpolateParams,
+			},
+			expected: "bar_WPROD:secret@tcp(master.local:3306)/bar?tls=true&parseTime=false&timeout=1s&readTimeout=100ms&" +
+				"writeTimeout=200ms&transaction_isolation=%27READ-COMMITTED%27&interpolateParams=true",
+		},
+		{
+			name:       "interpolate params disabled",
+			connection: Connection{InterpolateParams: &noInterpolateParams},
+			expected:   "bar_WPROD:secret@tcp(master.local:3306)/bar?interpolateParams=false&parseTime=true",
+		},
+		{
+			name: "parameters take precedence",
+			connection: Connection{
+				Parameters:  "tls=skip-verify&readTimeout=50ms",
+				TLS:         true,
+				ReadTimeout: Duration(100 * time.Millisecond),
+			},
+			expected: "bar_WPROD:secret@tcp(master.local:3306)/bar?tls=skip-verify&readTimeout=50ms&parseTime=true",
+		},
+		{
+			name: "parameters along with settings",
+			connection: Connection{
+				Parameters:     "charset=utf8mb4",
+				ConnectTimeout: Duration(2 * time.Second),
+			},
+			expected: "bar_WPROD:secret@tcp(master.local:3306)/bar?charset=utf8mb4&timeout=2s&parseTime=true",
+		},
+		{
+			name: "parameters map",
+			connection: Connection{
+				ParametersMap: map[string]string{
+					"time_zone": "'-03:00'",
+					"loc":       "America/Buenos_Aires",
+					"charset":   "utf8mb4",
+					"tls":       "skip-verify",
+				},
+				TLS:            true,
+				ConnectTimeout: Duration(2 * time.Second),
+			},
+			expected: "bar_WPROD:secret@tcp(master.local:3306)/bar?charset=utf8mb4&loc=America%2FBuenos_Aires&" +
+				"time_zone=%27-03%3A00%27&tls=skip-verify&timeout=2s&parseTime=true",
+		},
+	}
+
+	e := endpoint{host: "master.local:3306", role: "WPROD", username: "bar_WPROD", password: "secret"}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, buildDSN(e, "bar", tc.connection))
+		})
+	}
+}
+
+func TestBuildDSN_Hosts(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{host: "10.0.0.1:3306", expected: "bar_WPROD:secret@tcp(10.0.0.1:3306)/bar?parseTime=true"},
+		{host: "[::1]:3306", expected: "bar_WPROD:secret@tcp([::1]:3306)/bar?parseTime=true"},
+		{host: "::1", expected: "bar_WPROD:secret@tcp([::1]:3306)/bar?parseTime=true"},
+		{host: "[::1]", expected: "bar_WPROD:secret@tcp([::1]:3306)/bar?parseTime=true"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			e := endpoint{host: tc.host, role: "WPROD", username: "bar_WPROD", password: "secret"}
+			require.Equal(t, tc.expected, buildDSN(e, "bar", Connection{}))
+		})
+	}
+}
+
+func TestUnsafeInterpolationCharset(t *testing.T) {
+	interpolateParams, noInterpolateParams := true, false
+
+	testCases := []struct {
+		name       string
+		connection Connection
+		expected   string
+	}{
+		{
+			name:       "without charset",
+			connection: Connection{InterpolateParams: &interpolateParams},
+		},
+		{
+			name:       "safe charset",
+			connection: Connection{InterpolateParams: &interpolateParams, Parameters: "charset=utf8mb4"},
+		},
+		{
+			name:       "unsafe charset",
+			connection: Connection{InterpolateParams: &interpolateParams, Parameters: "charset=utf8mb4,GBK"},
+			expected:   "gbk",
+		},
+		{
+			name: "unsafe collation",
+			connection: Connection{
+				InterpolateParams: &interpolateParams,
+				ParametersMap:     map[string]string{"collation": "sjis_japanese_ci"},
+			},
+			expected: "sjis",
+		},
+		{
+			name:       "interpolate params disabled",
+			connection: Connection{InterpolateParams: &noInterpolateParams, Parameters: "charset=big5"},
+		},
+		{
+			name:       "interpolate params not set",
+			connection: Connection{Parameters: "charset=big5"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			charset, ok := unsafeInterpolationCharset(tc.connection)
+			require.Equal(t, tc.expected, charset)
+			require.Equal(t, tc.expected != "", ok)
+		})
+	}
+}
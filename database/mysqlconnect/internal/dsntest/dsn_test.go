@@ -0,0 +1,66 @@
+// Package dsntest verifies the DSNs built by mysqlconnect with the github.com/go-sql-driver/mysql driver.
+// It is a separate package since importing the driver registers it as mysql, the name under which
+// the tests of mysqlconnect register their mock driver.
+package dsntest
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/JhonX2011/GOWebApplication/database/mysqlconnect"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+// addrDriver records the address of the server parsed by the driver from the DSN of the connection.
+type addrDriver struct {
+	driver.Driver
+	addr *string
+}
+
+func (d addrDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	*d.addr = config.Addr
+
+	return d.Driver.(driver.DriverContext).OpenConnector(dsn)
+}
+
+func TestBuildDSN_ParsedAddress(t *testing.T) {
+	testCases := []struct {
+		name     string
+		endpoint string
+		port     int
+		expected string
+	}{
+		{name: "IPv4", endpoint: "10.0.0.1", expected: "10.0.0.1:3306"},
+		{name: "IPv4 and port", endpoint: "10.0.0.1:3307", expected: "10.0.0.1:3307"},
+		{name: "host name", endpoint: "mysql.local", expected: "mysql.local:3306"},
+		{name: "unbracketed IPv6", endpoint: "::1", expected: "[::1]:3306"},
+		{name: "unbracketed IPv6 and Port", endpoint: "::1", port: 3307, expected: "[::1]:3307"},
+		{name: "bracketed IPv6", endpoint: "[::1]", expected: "[::1]:3306"},
+		{name: "bracketed IPv6 and port", endpoint: "[::1]:3307", expected: "[::1]:3307"},
+		{name: "unbracketed IPv6 ending like a port", endpoint: "2001:db8::1:3306", expected: "[2001:db8::1:3306]:3306"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", tc.endpoint)
+
+			var addr string
+			connections, err := mysqlconnect.Open(mysqlconnect.Config{
+				Cluster:     "desaenv08",
+				Schema:      "bar",
+				Connections: []mysqlconnect.Connection{{Name: "master", IsMaster: true, Port: tc.port}},
+			}, mysqlconnect.WithDriverWrapper(func(name string, d driver.Driver) driver.Driver {
+				return addrDriver{Driver: d, addr: &addr}
+			}))
+			require.NoError(t, err)
+			require.NoError(t, connections.Close())
+
+			require.Equal(t, tc.expected, addr)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"golang.org/x/exp/maps"
+)
+
+// _defaultLeakWindow is how long the in-use connections must stay above the threshold to be reported
+// when WithLeakDetection is not used.
+const _defaultLeakWindow = time.Minute
+
+// statsSource provides the database statistics of every connection by name.
+type statsSource interface {
+	Stats() map[string]sql.DBStats
+}
+
+// leakDetector reports the connections whose in-use connections stay pinned above a threshold.
+type leakDetector struct {
+	source    statsSource
+	logger    logger.Logger
+	threshold int
+	window    time.Duration
+
+	// pinnedSince is when the in-use connections of each connection went above the threshold,
+	// and reported whether the connection was already reported since then.
+	pinnedSince map[string]time.Time
+	reported    map[string]bool
+}
+
+func newLeakDetector(source statsSource, l logger.Logger, threshold int, window time.Duration) *leakDetector {
+	if l == nil {
+		l = logger.NewLogger(logger.DefaultOSExit)
+	}
+	if window <= 0 {
+		window = _defaultLeakWindow
+	}
+
+	return &leakDetector{
+		source:      source,
+		logger:      l,
+		threshold:   threshold,
+		window:      window,
+		pinnedSince: make(map[string]time.Time),
+		reported:    make(map[string]bool),
+	}
+}
+
+// StartLeakDetector implements the Connections interface.
+func (c *connections) StartLeakDetector(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = _defaultSampleInterval
+	}
+	d := newLeakDetector(c, c.logger, c.leakThreshold, c.leakWindow)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				d.sample(now)
+			}
+		}
+	}()
+}
+
+// sample takes a sample of the statistics at now and reports the connections pinned for longer than the window.
+// Each connection is reported once until its in-use connections drop to the threshold or below.
+func (d *leakDetector) sample(now time.Time) {
+	stats := d.source.Stats()
+	names := maps.Keys(stats)
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := stats[name]
+		if !d.pinned(s) {
+			delete(d.pinnedSince, name)
+			delete(d.reported, name)
+			continue
+		}
+
+		since, ok := d.pinnedSince[name]
+		if !ok {
+			d.pinnedSince[name] = now
+			continue
+		}
+
+		if pinnedFor := now.Sub(since); pinnedFor >= d.window && !d.reported[name] {
+			d.reported[name] = true
+			d.logger.Warningf("MySQL connection %q may be leaking connections: %d connections in use for %s "+
+				"(max open: %d), check that every *sql.Rows, *sql.Tx and *sql.Conn is closed",
+				name, s.InUse, pinnedFor, s.MaxOpenConnections)
+		}
+	}
+}
+
+// pinned reports whether the in-use connections of s are above the threshold, or at the pool limit
+// when there is no threshold.
+func (d *leakDetector) pinned(s sql.DBStats) bool {
+	if d.threshold > 0 {
+		return s.InUse > d.threshold
+	}
+	return s.MaxOpenConnections > 0 && s.InUse >= s.MaxOpenConnections
+}
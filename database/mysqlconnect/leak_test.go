@@ -0,0 +1,131 @@
+package mysqlconnect
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStatsSource map[string]sql.DBStats
+
+func (f fakeStatsSource) Stats() map[string]sql.DBStats {
+	return f
+}
+
+// syncBuffer is a bytes.Buffer safe to write from the leak detector goroutine while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLeakDetector(t *testing.T) {
+	source := fakeStatsSource{
+		"foo": {InUse: 6, MaxOpenConnections: 10},
+		"bar": {InUse: 2, MaxOpenConnections: 10},
+	}
+	output := new(bytes.Buffer)
+	d := newLeakDetector(source, logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo()), 5, time.Minute)
+
+	start := time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)
+	d.sample(start)
+	d.sample(start.Add(30 * time.Second))
+	require.Empty(t, output.String(), "the connections are not pinned for long enough")
+
+	d.sample(start.Add(time.Minute))
+	require.Contains(t, output.String(), `MySQL connection "foo" may be leaking connections: 6 connections in use for 1m0s (max open: 10)`)
+	require.NotContains(t, output.String(), `"bar"`)
+
+	// A pinned connection is only reported once.
+	output.Reset()
+	d.sample(start.Add(2 * time.Minute))
+	require.Empty(t, output.String())
+
+	// Once the in-use connections drop, the window starts over.
+	source["foo"] = sql.DBStats{InUse: 1, MaxOpenConnections: 10}
+	d.sample(start.Add(3 * time.Minute))
+	source["foo"] = sql.DBStats{InUse: 7, MaxOpenConnections: 10}
+	d.sample(start.Add(4 * time.Minute))
+	d.sample(start.Add(4*time.Minute + 30*time.Second))
+	require.Empty(t, output.String())
+
+	d.sample(start.Add(5 * time.Minute))
+	require.Contains(t, output.String(), `MySQL connection "foo" may be leaking connections: 7 connections in use for 1m0s`)
+}
+
+func TestLeakDetector_DefaultThreshold(t *testing.T) {
+	source := fakeStatsSource{
+		"saturated": {InUse: 10, MaxOpenConnections: 10},
+		"unlimited": {InUse: 100},
+	}
+	output := new(bytes.Buffer)
+	d := newLeakDetector(source, logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo()), 0, 0)
+
+	start := time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)
+	d.sample(start)
+	d.sample(start.Add(_defaultLeakWindow))
+
+	require.Contains(t, output.String(), `MySQL connection "saturated" may be leaking connections`)
+	require.NotContains(t, output.String(), `"unlimited"`)
+}
+
+func TestConnections_StartLeakDetector(t *testing.T) {
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{CloseFunc: func() error { return nil }}, nil
+	}
+
+	output := new(syncBuffer)
+	maxOpen := 1
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{Name: "foo", ConnectionPool: ConnectionPool{MaxOpenConnections: &maxOpen}},
+		},
+	}, WithLogger(logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())),
+		WithLeakDetection(0, 20*time.Millisecond))
+	require.NoError(t, err)
+
+	// The connection is never returned to the pool, as if the rows of a query were not closed.
+	conn, err := connections.Conn(context.Background(), "foo")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	connections.StartLeakDetector(ctx, 5*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(output.String(), `MySQL connection "foo" may be leaking connections: 1 connections in use`)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestConnections_StartLeakDetectorDefaultInterval(t *testing.T) {
+	connections := NewStatic(map[string]*sql.DB{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A non-positive interval must not make the ticker panic.
+	connections.StartLeakDetector(ctx, 0)
+	time.Sleep(20 * time.Millisecond)
+}
@@ -0,0 +1,334 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"time"
+)
+
+// The classes of the errors reported by QueryMetrics.IncErrors.
+const (
+	// ErrorClassCanceled is the class of the queries canceled by their context.
+	ErrorClassCanceled = "canceled"
+	// ErrorClassTimeout is the class of the queries that exceeded the deadline of their context or a network timeout.
+	ErrorClassTimeout = "timeout"
+	// ErrorClassBadConnection is the class of the queries that failed because the connection was broken.
+	ErrorClassBadConnection = "bad_connection"
+	// ErrorClassQuery is the class of the rest of the errors, usually reported by the server, such as syntax errors
+	// or constraint violations.
+	ErrorClassQuery = "query"
+)
+
+// QueryMetrics receives the metrics of the queries executed by the connections, segmented by connection name,
+// for example backed by Prometheus counters and a histogram. It must be safe for concurrent use.
+type QueryMetrics interface {
+	// IncQueries is called once per executed query or statement.
+	IncQueries(connection string)
+	// IncErrors is called once per query or statement that failed, with the class of its error,
+	// one of ErrorClassCanceled, ErrorClassTimeout, ErrorClassBadConnection or ErrorClassQuery.
+	IncErrors(connection, class string)
+	// ObserveLatency is called once per executed query or statement with the time it took to execute it.
+	// For queries returning rows, it doesn't include the time spent reading the rows.
+	ObserveLatency(connection string, d time.Duration)
+}
+
+// WithQueryMetrics records into m the number of queries executed, the errors returned and the latency of every
+// connection. It wraps the driver with WithDriverWrapper, so the connections opened without it have no overhead.
+// Preparing statements, beginning transactions and pinging are not recorded.
+func WithQueryMetrics(m QueryMetrics) Option {
+	return WithDriverWrapper(func(name string, d driver.Driver) driver.Driver {
+		return &metricsDriver{name: name, driver: d, metrics: m}
+	})
+}
+
+// classifyError returns the class of err reported by QueryMetrics.IncErrors.
+func classifyError(err error) string {
+	var timeout interface{ Timeout() bool }
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeout) && timeout.Timeout():
+		return ErrorClassTimeout
+	case errors.Is(err, driver.ErrBadConn):
+		return ErrorClassBadConnection
+	default:
+		return ErrorClassQuery
+	}
+}
+
+// metricsDriver is a driver.Driver whose connections record the metrics of their queries.
+type metricsDriver struct {
+	name    string
+	driver  driver.Driver
+	metrics QueryMetrics
+}
+
+var (
+	_ driver.DriverContext = &metricsDriver{}
+	_ driver.Connector     = &metricsConnector{}
+)
+
+func (d *metricsDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.driver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConn{Conn: conn, driver: d}, nil
+}
+
+func (d *metricsDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	dc, ok := d.driver.(driver.DriverContext)
+	if !ok {
+		return &metricsConnector{Connector: dsnConnector{dsn: dsn, driver: d.driver}, driver: d}, nil
+	}
+
+	connector, err := dc.OpenConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConnector{Connector: connector, driver: d}, nil
+}
+
+// observe records the metrics of a query that started at start and returned err. The queries that
+// returned driver.ErrSkip are not recorded, since they were not executed but retried by database/sql.
+func (d *metricsDriver) observe(start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	d.metrics.IncQueries(d.name)
+	d.metrics.ObserveLatency(d.name, time.Since(start))
+	if err != nil {
+		d.metrics.IncErrors(d.name, classifyError(err))
+	}
+}
+
+// metricsConnector is the driver.Connector of a metricsDriver.
+type metricsConnector struct {
+	driver.Connector
+	driver *metricsDriver
+}
+
+func (c *metricsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsConn{Conn: conn, driver: c.driver}, nil
+}
+
+func (c *metricsConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the wrapped connector if it holds any resources, since sql.DB.Close only closes
+// the connectors that implement io.Closer.
+func (c *metricsConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// metricsConn is a driver.Conn recording the metrics of its queries. It implements every optional interface
+// of driver.Conn, falling back to the behavior of database/sql when the wrapped connection doesn't.
+type metricsConn struct {
+	driver.Conn
+	driver *metricsDriver
+}
+
+var (
+	_ driver.ExecerContext      = &metricsConn{}
+	_ driver.QueryerContext     = &metricsConn{}
+	_ driver.ConnPrepareContext = &metricsConn{}
+	_ driver.ConnBeginTx        = &metricsConn{}
+	_ driver.Pinger             = &metricsConn{}
+	_ driver.SessionResetter    = &metricsConn{}
+	_ driver.Validator          = &metricsConn{}
+	_ driver.NamedValueChecker  = &metricsConn{}
+)
+
+func (c *metricsConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsStmt{Stmt: stmt, conn: c.Conn, driver: c.driver}, nil
+}
+
+func (c *metricsConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	preparer, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return c.Prepare(query)
+	}
+
+	stmt, err := preparer.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsStmt{Stmt: stmt, conn: c.Conn, driver: c.driver}, nil
+}
+
+func (c *metricsConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support non-default isolation level or read-only transactions")
+	}
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+func (c *metricsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	c.driver.observe(start, err)
+	return result, err
+}
+
+func (c *metricsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.driver.observe(start, err)
+	return rows, err
+}
+
+func (c *metricsConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *metricsConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *metricsConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *metricsConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// metricsStmt is a driver.Stmt recording the metrics of its executions.
+type metricsStmt struct {
+	driver.Stmt
+	conn   driver.Conn
+	driver *metricsDriver
+}
+
+var (
+	_ driver.StmtExecContext   = &metricsStmt{}
+	_ driver.StmtQueryContext  = &metricsStmt{}
+	_ driver.NamedValueChecker = &metricsStmt{}
+	_ driver.ColumnConverter   = &metricsStmt{}
+)
+
+func (s *metricsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	result, err := s.Stmt.Exec(args) //nolint:staticcheck
+	s.driver.observe(start, err)
+	return result, err
+}
+
+func (s *metricsStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.Stmt.Query(args) //nolint:staticcheck
+	s.driver.observe(start, err)
+	return rows, err
+}
+
+func (s *metricsStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValues(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Exec(values)
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	s.driver.observe(start, err)
+	return result, err
+}
+
+func (s *metricsStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValues(ctx, args)
+		if err != nil {
+			return nil, err
+		}
+		return s.Query(values)
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	s.driver.observe(start, err)
+	return rows, err
+}
+
+// CheckNamedValue checks value with the statement or, as database/sql does when the statement is not
+// a driver.NamedValueChecker, with the connection, since database/sql only asks the statement wrapper.
+func (s *metricsStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	if checker, ok := s.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (s *metricsStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if converter, ok := s.Stmt.(driver.ColumnConverter); ok { //nolint:staticcheck
+		return converter.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// namedValues returns the values of args for the statements that don't support named arguments,
+// as database/sql does, or the error of ctx if it is already done.
+func namedValues(ctx context.Context, args []driver.NamedValue) ([]driver.Value, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,134 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueryMetrics records the metrics by connection name.
+type fakeQueryMetrics struct {
+	mu        sync.Mutex
+	queries   map[string]int
+	errors    map[string]int // by connection:class
+	latencies map[string]int
+}
+
+func newFakeQueryMetrics() *fakeQueryMetrics {
+	return &fakeQueryMetrics{
+		queries:   make(map[string]int),
+		errors:    make(map[string]int),
+		latencies: make(map[string]int),
+	}
+}
+
+func (m *fakeQueryMetrics) IncQueries(connection string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queries[connection]++
+}
+
+func (m *fakeQueryMetrics) IncErrors(connection, class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors[connection+":"+class]++
+}
+
+func (m *fakeQueryMetrics) ObserveLatency(connection string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if d >= 0 {
+		m.latencies[connection]++
+	}
+}
+
+func TestWithQueryMetrics(t *testing.T) {
+	results := map[string]error{
+		"INSERT INTO users (id) VALUES (1)": nil,
+		"INSERT INTO users (id) VALUES (2)": errors.New("Error 1062: Duplicate entry '2' for key 'PRIMARY'"),
+		"UPDATE users SET name = 'foo'":     context.DeadlineExceeded,
+		"DELETE FROM users":                 context.Canceled,
+	}
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						if err := results[query]; err != nil {
+							return nil, err
+						}
+						return driver.RowsAffected(1), nil
+					},
+					QueryFunc: func(args []driver.Value) (driver.Rows, error) {
+						return &mocks.DriverRowsMock{}, nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	metrics := newFakeQueryMetrics()
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "master_rw"}, {Name: "replica_ro"}},
+	}, WithQueryMetrics(metrics))
+	require.NoError(t, err)
+	defer connections.Close()
+
+	ctx := context.Background()
+	master, err := connections.Get("master_rw")
+	require.NoError(t, err)
+	for query := range results {
+		_, _ = master.ExecContext(ctx, query)
+	}
+
+	replica, err := connections.Get("replica_ro")
+	require.NoError(t, err)
+	rows, err := replica.QueryContext(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.NoError(t, replica.PingContext(ctx))
+
+	require.Equal(t, map[string]int{"master_rw": 4, "replica_ro": 1}, metrics.queries)
+	require.Equal(t, map[string]int{"master_rw": 4, "replica_ro": 1}, metrics.latencies)
+	require.Equal(t, map[string]int{
+		"master_rw:query":    1,
+		"master_rw:timeout":  1,
+		"master_rw:canceled": 1,
+	}, metrics.errors)
+}
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected string
+	}{
+		{err: context.Canceled, expected: ErrorClassCanceled},
+		{err: fmt.Errorf("query failed: %w", context.DeadlineExceeded), expected: ErrorClassTimeout},
+		{err: &net.OpError{Op: "read", Err: timeoutError{}}, expected: ErrorClassTimeout},
+		{err: driver.ErrBadConn, expected: ErrorClassBadConnection},
+		{err: errors.New("Error 1064: You have an error in your SQL syntax"), expected: ErrorClassQuery},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			require.Equal(t, tc.expected, classifyError(tc.err))
+		})
+	}
+}
+
+// timeoutError is a network timeout error.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
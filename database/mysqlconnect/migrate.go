@@ -0,0 +1,229 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JhonX2011/GOWebApplication/database/internal/sqllex"
+)
+
+const (
+	// _migrationsLock is the name of the MySQL lock held while the migrations are applied.
+	_migrationsLock = "schema_migrations"
+	// _migrationsLockTimeout is how long, in seconds, Migrate waits for the lock held by another instance.
+	_migrationsLockTimeout = 60
+)
+
+// migration is a migration file of the fs.FS given to Migrate.
+type migration struct {
+	version int64
+	file    string
+}
+
+// Migrate implements the Connections interface.
+//
+// The migrations are the SQL files at the root of migrations named after their version followed by an
+// underscore, such as 0001_create_users.sql or 0001_create_users.up.sql. The rest of the files, including
+// the down-migrations (.down.sql), are ignored. The files may contain several statements separated by
+// semicolons, which are executed in order.
+//
+// Each migration is applied within a transaction along with the insertion of its version into schema_migrations,
+// which is created if it doesn't exist. MySQL implicitly commits the statements that change the schema, such as
+// CREATE TABLE, so a migration failing after one of them is partially applied and must be fixed by hand.
+// The migrations already applied are skipped, so it can be called on every startup. The instances starting at
+// the same time apply the migrations one at a time, holding a MySQL named lock.
+func (c *connections) Migrate(ctx context.Context, name string, migrations fs.FS) error {
+	db, err := c.Get(name)
+	if err != nil {
+		return err
+	}
+
+	pending, err := readMigrations(migrations)
+	if err != nil {
+		return err
+	}
+
+	// The named lock is held by the session, so everything is done on the same connection.
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection %q: %w", name, err)
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", _migrationsLock, _migrationsLockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to lock the migrations: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return fmt.Errorf("failed to lock the migrations: timed out after %ds", _migrationsLockTimeout)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", _migrationsLock) //nolint:errcheck
+
+	if _, err := conn.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version BIGINT NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)"); err != nil {
+		return fmt.Errorf("failed to create the schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range pending {
+		if applied[m.version] {
+			continue
+		}
+
+		start := time.Now()
+		if err := applyMigration(ctx, conn, migrations, m); err != nil {
+			return fmt.Errorf("failed to apply migration %s: %w", m.file, err)
+		}
+
+		if c.logger != nil {
+			c.logger.Infof("MySQL migration applied | connection: %s | version: %d | file: %s | duration: %s",
+				name, m.version, m.file, time.Since(start))
+		}
+	}
+
+	return nil
+}
+
+// readMigrations returns the up-migrations at the root of migrations sorted by version.
+func readMigrations(migrations fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(migrations, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the migrations: %w", err)
+	}
+
+	var result []migration
+	files := make(map[int64]string)
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(file, ".sql") || strings.HasSuffix(file, ".down.sql") {
+			continue
+		}
+
+		prefix, _, ok := strings.Cut(file, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %s: it must start with the version followed by _", file)
+		}
+
+		if previous, ok := files[version]; ok {
+			return nil, fmt.Errorf("invalid migration file name %s: version %d is already used by %s", file, version, previous)
+		}
+		files[version] = file
+
+		result = append(result, migration{version: version, file: file})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].version < result[j].version })
+
+	return result, nil
+}
+
+// appliedVersions returns the versions of the migrations recorded in schema_migrations.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+// applyMigration executes the statements of m and records its version within a transaction.
+func applyMigration(ctx context.Context, conn *sql.Conn, migrations fs.FS, m migration) error {
+	content, err := fs.ReadFile(migrations, m.file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, statement := range splitStatements(string(content)) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", m.version); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits script into its statements separated by semicolons, ignoring the semicolons inside
+// quoted strings and identifiers. The comments are removed, except the /*! executable comments of MySQL,
+// so that a script ending with a comment doesn't produce an empty query. The statements are trimmed,
+// dropping the empty ones.
+func splitStatements(script string) []string {
+	var statements []string
+	var b strings.Builder
+	flush := func() {
+		if statement := strings.TrimSpace(b.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		b.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := sqllex.QuoteEnd(script, i)
+			b.WriteString(script[i:end])
+			i = end - 1
+		case c == '#' || isDashComment(script[i:]):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			i += end - 1
+		case strings.HasPrefix(script[i:], "/*") && !strings.HasPrefix(script[i:], "/*!"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 4
+			}
+			b.WriteByte(' ')
+			i += end + 3
+		case c == ';':
+			flush()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+
+	return statements
+}
+
+// isDashComment reports whether s starts with a -- comment, which MySQL requires to be followed
+// by a whitespace or a control character, so that 1--1 is not a comment.
+func isDashComment(s string) bool {
+	return strings.HasPrefix(s, "--") && (len(s) == 2 || s[2] <= ' ')
+}
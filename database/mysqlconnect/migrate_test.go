@@ -0,0 +1,163 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+const _createMigrationsTable = "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+	"version BIGINT NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)"
+
+func givenMigrationsMock(t *testing.T, applied ...int64) (Connections, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	mock.ExpectQuery("SELECT GET_LOCK(?, ?)").
+		WithArgs("schema_migrations", 60).
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(1))
+	mock.ExpectExec(_createMigrationsTable).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, version := range applied {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(rows)
+
+	return NewStatic(map[string]*sql.DB{"master": db}), mock
+}
+
+func TestConnections_Migrate(t *testing.T) {
+	migrations := fstest.MapFS{
+		"0001_create_users.sql":      {Data: []byte("CREATE TABLE users (id BIGINT PRIMARY KEY);")},
+		"0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0002_add_email.up.sql":      {Data: []byte("-- The email is optional.\nALTER TABLE users ADD COLUMN email VARCHAR(255);\n")},
+		"0010_seed.sql": {Data: []byte("INSERT INTO users (id, email) VALUES (1, 'a;b@example.com');\n" +
+			"INSERT INTO users (id) VALUES (2)")},
+		"README.md": {Data: []byte("# Migrations")},
+	}
+
+	connections, mock := givenMigrationsMock(t, 1)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE users ADD COLUMN email VARCHAR(255)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations (version) VALUES (?)").WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO users (id, email) VALUES (1, 'a;b@example.com')").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO users (id) VALUES (2)").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectExec("INSERT INTO schema_migrations (version) VALUES (?)").WithArgs(10).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectExec("SELECT RELEASE_LOCK(?)").WithArgs("schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, connections.Migrate(context.Background(), "master", migrations))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnections_Migrate_AlreadyApplied(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_create_users.sql": {Data: []byte("CREATE TABLE users (id BIGINT PRIMARY KEY)")},
+	}
+
+	connections, mock := givenMigrationsMock(t, 1)
+	mock.ExpectExec("SELECT RELEASE_LOCK(?)").WithArgs("schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, connections.Migrate(context.Background(), "master", migrations))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnections_Migrate_Error(t *testing.T) {
+	migrations := fstest.MapFS{
+		"1_create_users.sql": {Data: []byte("CREATE TABLE users (id BIGINT PRIMARY KEY)")},
+		"2_seed.sql":         {Data: []byte("INSERT INTO users (id) VALUES (1)")},
+	}
+
+	connections, mock := givenMigrationsMock(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE users (id BIGINT PRIMARY KEY)").WillReturnError(errors.New("table already exists"))
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT RELEASE_LOCK(?)").WithArgs("schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := connections.Migrate(context.Background(), "master", migrations)
+	require.EqualError(t, err, "failed to apply migration 1_create_users.sql: table already exists")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConnections_Migrate_InvalidFiles(t *testing.T) {
+	testCases := []struct {
+		name       string
+		migrations fstest.MapFS
+		errMessage string
+	}{
+		{
+			name:       "missing version",
+			migrations: fstest.MapFS{"create_users.sql": {}},
+			errMessage: "invalid migration file name create_users.sql: it must start with the version followed by _",
+		},
+		{
+			name:       "duplicated version",
+			migrations: fstest.MapFS{"01_create_users.sql": {}, "1_create_admins.sql": {}},
+			errMessage: "invalid migration file name 1_create_admins.sql: version 1 is already used by 01_create_users.sql",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			connections := NewStatic(map[string]*sql.DB{"master": db})
+			require.EqualError(t, connections.Migrate(context.Background(), "master", tc.migrations), tc.errMessage)
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		script   string
+		expected []string
+	}{
+		{
+			name:     "single statement without semicolon",
+			script:   "CREATE TABLE users (id BIGINT)",
+			expected: []string{"CREATE TABLE users (id BIGINT)"},
+		},
+		{
+			name:     "semicolons in quotes",
+			script:   "INSERT INTO t VALUES ('a;b', \"c;d\");\nUPDATE `x;y` SET v = 'it''s; fine';",
+			expected: []string{"INSERT INTO t VALUES ('a;b', \"c;d\")", "UPDATE `x;y` SET v = 'it''s; fine'"},
+		},
+		{
+			name:     "comments",
+			script:   "-- first; comment\nSELECT 1; # second; comment\nSELECT /* inline; */ 2;\n-- trailing comment",
+			expected: []string{"SELECT 1", "SELECT   2"},
+		},
+		{
+			name:     "executable comments are kept",
+			script:   "/*!40101 SET NAMES utf8 */;\nSELECT 1--1;",
+			expected: []string{"/*!40101 SET NAMES utf8 */", "SELECT 1--1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, splitStatements(tc.script))
+		})
+	}
+}
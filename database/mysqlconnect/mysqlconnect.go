@@ -1,15 +1,23 @@
 package mysqlconnect
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
 	"golang.org/x/exp/maps"
 )
 
@@ -75,6 +83,22 @@ type Config struct {
 	// If IsMaster is false and IsReadOnly is false the Open function will return an error since
 	// it would make no sense to create a connection to a replica with read-write permissions.
 	Connections []Connection `json:"connections"`
+	// DefaultConnectionPool is the connection pool configuration shared by all the connections.
+	// Each field is applied to any connection that doesn't set it in its own connection_pool,
+	// meaning that the values defined per connection always take precedence.
+	// It is optional.
+	DefaultConnectionPool *ConnectionPool `json:"default_connection_pool"`
+	// EnsureParseTime adds parseTime=true to the DSN parameters unless the parseTime parameter is already set.
+	// With parseTime=true the driver scans DATE and DATETIME columns into time.Time instead of []byte.
+	// It is only used with DSN, since it is always enabled when using either a Cluster or a HACluster.
+	EnsureParseTime bool `json:"ensure_parse_time"`
+	// BestEffort makes Open ping every connection and skip the ones that fail to open or to respond, for
+	// example a dead replica, instead of failing entirely. The skipped connections are logged with the logger
+	// of WithLogger and reported by a *PartialOpenError returned along with the Connections that opened.
+	// Open still fails with ErrNoConnectionOpened if none of the connections opens, since the Connections
+	// would be unusable. Configuration errors are never skipped.
+	// It is optional and disabled by default.
+	BestEffort bool `json:"best_effort"`
 }
 
 // Connection defines a connection to a MySQL database.
@@ -85,14 +109,93 @@ type Connection struct {
 	// It is ignored when using DSN.
 	IsMaster bool `json:"is_master"`
 	// IsReadOnly indicates whether the connection is read-only.
+	// At least one of IsMaster and IsReadOnly must be true, since a replica cannot be written.
+	// Both can be true for a read-only connection to the master.
 	// It is ignored when using DSN.
 	IsReadOnly bool `json:"is_read_only"`
+	// Weight is the share of the Connections.GetReadOnly selections that go to this connection,
+	// relative to the weights of the other read-only connections. For example, with weights 7 and 3
+	// the connections get 70% and 30% of the selections. It is only used when IsReadOnly is true.
+	// It is optional and defaults to 1, meaning that the selections are evenly distributed.
+	Weight int `json:"weight"`
 	// Parameters are the connection parameters in the form of param1=value1&...&paramN=valueN.
+	// They are specific to the database engine, so the engine agnostic fields such as TLS or ReadTimeout
+	// are preferred, but when both set the same parameter, Parameters takes precedence.
 	// For example: parseTime=true&readTimeout=100ms&timeout=100ms&writeTimeout=100ms
+	// parseTime=true is always added unless the parseTime parameter is explicitly set, meaning that
+	// DATE and DATETIME columns are scanned into time.Time instead of []byte. Set parseTime=false to opt out.
 	// It is optional and ignored when using DSN.
 	Parameters string `json:"parameters"`
+	// ParametersMap are the connection parameters by name, an alternative to Parameters for programmatic
+	// configs where the names and values are escaped and joined by the package, e.g.
+	// {"loc": "America/Buenos_Aires", "time_zone": "'-03:00'"}. It is mutually exclusive with Parameters
+	// and takes the same precedence over the engine agnostic fields.
+	// It is optional and ignored when using DSN.
+	ParametersMap map[string]string `json:"parameters_map"`
+	// Port is the port of the MySQL server. It is only used when the endpoint environment variable of the
+	// cluster contains just the host, otherwise the port of the endpoint is kept.
+	// It is optional and ignored when using DSN.
+	Port int `json:"port"`
+	// TLS enables TLS on the connections to the server, verifying its certificate.
+	// It is optional and ignored when using DSN.
+	TLS bool `json:"tls"`
+	// ParseTime sets whether DATE and DATETIME columns are scanned into time.Time. It is optional and
+	// defaults to true, and it is ignored when using DSN, where EnsureParseTime is used instead.
+	ParseTime *bool `json:"parse_time"`
+	// ConnectTimeout is the maximum amount of time to establish a connection to the server.
+	// It is optional and ignored when using DSN.
+	ConnectTimeout Duration `json:"connect_timeout"`
+	// ReadTimeout is the maximum amount of time to wait for the server to respond.
+	// It is optional and ignored when using DSN.
+	ReadTimeout Duration `json:"read_timeout"`
+	// WriteTimeout is the maximum amount of time to send a query to the server.
+	// It is optional and ignored when using DSN.
+	WriteTimeout Duration `json:"write_timeout"`
+	// DefaultIsolation is the default transaction isolation level of the sessions of the connection, one of
+	// READ-UNCOMMITTED, READ-COMMITTED, REPEATABLE-READ or SERIALIZABLE, for example READ-COMMITTED for
+	// read-heavy replicas. The transactions started with an explicit isolation level still use their own.
+	// It is optional, the server default is used when it is empty, and it is ignored when using DSN.
+	DefaultIsolation string `json:"default_isolation"`
+	// InterpolateParams sets whether the driver replaces the placeholders of the queries with their escaped
+	// arguments, instead of preparing a statement on the server, executing it and closing it, which saves
+	// two round-trips per query with arguments, for example on read-heavy replicas. The queries are then
+	// sent as text, so the server doesn't cache their plans as prepared statements.
+	// The escaping is not safe with the multibyte charsets big5, cp932, gb2312, gb18030, gbk and sjis, so
+	// it can't be enabled along with them or their collations in the parameters.
+	// It is optional, the driver default, false, is used when it is not set, and it is ignored when using DSN.
+	InterpolateParams *bool `json:"interpolate_params"`
+	// InitStatements are executed in order on every new connection of the pool, right after it is opened
+	// and before it is used, for example to set session variables: SET SESSION sql_mode='STRICT_ALL_TABLES'.
+	// If any of them fails, the connection is discarded and the error is returned by the query that needed it.
+	// It is optional.
+	InitStatements []string `json:"init_statements"`
 	// ConnectionPool is the configuration for a MySQL connection pool usually used by the database/sql package.
 	ConnectionPool ConnectionPool `json:"connection_pool"`
+	// AcquireTimeout is the maximum amount of time the BoundedDB returned by Connections.GetBounded waits
+	// for a free connection of the pool. It is optional and defaults to 1s.
+	AcquireTimeout Duration `json:"acquire_timeout"`
+	// HealthQuery is the query executed by Connections.Ping and Connections.PingAll to verify the connection,
+	// for example SELECT 1 FROM critical_table LIMIT 1 to check that the schema is accessible, not only
+	// that the server accepts connections. Its rows are discarded, so a query returning no rows succeeds.
+	// It is optional, when empty the connection is pinged instead.
+	HealthQuery string `json:"health_query"`
+	// CircuitBreaker is the configuration of the circuit breaker returned by Connections.GetWithBreaker.
+	// It is optional, the default values are used when it is not defined.
+	CircuitBreaker *CircuitBreakerConfig `json:"circuit_breaker"`
+	// Retry is the configuration of the RetryDB returned by Connections.GetWithRetry.
+	// It is optional, the default values are used when it is not defined.
+	Retry *RetryConfig `json:"retry"`
+}
+
+// clone returns a copy of c that doesn't share its InitStatements nor its ParametersMap.
+func (c Connection) clone() Connection {
+	if c.InitStatements != nil {
+		c.InitStatements = append([]string(nil), c.InitStatements...)
+	}
+	if c.ParametersMap != nil {
+		c.ParametersMap = maps.Clone(c.ParametersMap)
+	}
+	return c
 }
 
 // ConnectionPool is the configuration for a MySQL connection pool usually used by the database/sql package.
@@ -107,6 +210,99 @@ type ConnectionPool struct {
 	ConnMaxIdleTime *Duration `json:"conn_max_idle_time"`
 }
 
+// String returns the pool settings in the form of name=value pairs.
+// Settings that are not defined are reported as default.
+func (p ConnectionPool) String() string {
+	settings := []string{"default", "default", "default", "default"}
+	if p.ConnMaxLifetime != nil {
+		settings[0] = time.Duration(*p.ConnMaxLifetime).String()
+	}
+
+	if p.MaxIdleConnections != nil {
+		settings[1] = strconv.Itoa(*p.MaxIdleConnections)
+	}
+
+	if p.MaxOpenConnections != nil {
+		settings[2] = strconv.Itoa(*p.MaxOpenConnections)
+	}
+
+	if p.ConnMaxIdleTime != nil {
+		settings[3] = time.Duration(*p.ConnMaxIdleTime).String()
+	}
+
+	return fmt.Sprintf("conn_max_lifetime=%s max_idle_connections=%s max_open_connections=%s conn_max_idle_time=%s",
+		settings[0], settings[1], settings[2], settings[3])
+}
+
+// defaultMaxIdleConns is the process-wide default set by SetDefaultMaxIdleConns, nil when unset.
+var defaultMaxIdleConns atomic.Pointer[int] //nolint:gochecknoglobals
+
+// SetDefaultMaxIdleConns sets the maximum number of idle connections of the pools opened afterwards by Open
+// whose MaxIdleConnections is set neither in their connection_pool nor in the default_connection_pool.
+// It caps the idle connections of processes running many Connections, which would otherwise keep
+// the 2 idle connections per pool of the database/sql package each. A negative n unsets the default.
+// The pools that are already open are not affected.
+func SetDefaultMaxIdleConns(n int) {
+	if n < 0 {
+		defaultMaxIdleConns.Store(nil)
+		return
+	}
+	defaultMaxIdleConns.Store(&n)
+}
+
+// poolSetter is implemented by *sql.DB to set the connection pool parameters.
+type poolSetter interface {
+	SetConnMaxLifetime(d time.Duration)
+	SetMaxIdleConns(n int)
+	SetMaxOpenConns(n int)
+	SetConnMaxIdleTime(d time.Duration)
+}
+
+// applyPool sets the connection pool parameters of db that are defined in pool.
+func applyPool(db poolSetter, pool ConnectionPool) {
+	if pool.ConnMaxLifetime != nil {
+		db.SetConnMaxLifetime(time.Duration(*pool.ConnMaxLifetime))
+	}
+
+	if pool.MaxIdleConnections != nil {
+		db.SetMaxIdleConns(*pool.MaxIdleConnections)
+	}
+
+	if pool.MaxOpenConnections != nil {
+		db.SetMaxOpenConns(*pool.MaxOpenConnections)
+	}
+
+	if pool.ConnMaxIdleTime != nil {
+		db.SetConnMaxIdleTime(time.Duration(*pool.ConnMaxIdleTime))
+	}
+}
+
+// withDefaults returns a copy of the connection pool where every field that is not set
+// is taken from defaults. It returns the connection pool unchanged when defaults is nil.
+func (p ConnectionPool) withDefaults(defaults *ConnectionPool) ConnectionPool {
+	if defaults == nil {
+		return p
+	}
+
+	if p.ConnMaxLifetime == nil {
+		p.ConnMaxLifetime = defaults.ConnMaxLifetime
+	}
+
+	if p.MaxIdleConnections == nil {
+		p.MaxIdleConnections = defaults.MaxIdleConnections
+	}
+
+	if p.MaxOpenConnections == nil {
+		p.MaxOpenConnections = defaults.MaxOpenConnections
+	}
+
+	if p.ConnMaxIdleTime == nil {
+		p.ConnMaxIdleTime = defaults.ConnMaxIdleTime
+	}
+
+	return p
+}
+
 // Duration is a wrapper for time.Duration that allows it to be marshalled and unmarshalled from JSON as a string.
 // This type should not propagate beyond the scope of parsing the configuration.
 type Duration time.Duration
@@ -121,6 +317,11 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 func (d *Duration) UnmarshalJSON(bytes []byte) error {
 	var duration string
 	if err := json.Unmarshal(bytes, &duration); err != nil {
+		// A common mistake is writing the duration as a number, e.g. 600 instead of "600s".
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return fmt.Errorf("duration must be a string, got %s", typeErr.Value)
+		}
 		return err
 	}
 
@@ -133,17 +334,126 @@ func (d *Duration) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
+// LoadConfig reads the JSON file at path and unmarshalls it into a Config.
+// The returned error includes the path of the file when it can't be read or parsed.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read MySQL config file %q: %w", path, err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Config{}, fmt.Errorf("failed to parse MySQL config file %q: %w", path, err)
+	}
+
+	return config, nil
+}
+
 // Connections represents a set of connections to a MySQL database.
 type Connections interface {
 	// Get returns a connection to the MySQL database with the given name.
 	// The name must match the name of a connection defined in the configuration.
 	Get(name string) (*sql.DB, error)
 
+	// Has reports whether a connection with the given name was defined in the configuration.
+	Has(name string) bool
+
+	// GetWithBreaker returns the circuit breaker wrapping the connection with the given name.
+	// Queries executed through the breaker fast-fail with ErrBreakerOpen after a number of consecutive
+	// failures, until a cooldown period elapses. The breaker is shared by all the callers of the connection.
+	GetWithBreaker(name string) (*CircuitBreaker, error)
+
+	// GetBounded returns the BoundedDB wrapping the connection with the given name, whose queries fail
+	// with ErrPoolExhausted when no connection of the pool is available within its AcquireTimeout.
+	// It returns an error if the connection name is not defined.
+	GetBounded(name string) (*BoundedDB, error)
+
+	// GetWithRetry returns the RetryDB wrapping the connection with the given name, which retries
+	// the non locking read queries failing with a transient error. Writes are only retried if RetryWrites is set.
+	// It returns an error if the connection name is not defined.
+	GetWithRetry(name string) (*RetryDB, error)
+
+	// GetReadOnly returns one of the connections with IsReadOnly set to true, distributing the selections
+	// proportionally to their Weight. It returns ErrNoReadOnlyConnection if there are none.
+	GetReadOnly() (*sql.DB, error)
+
 	// List returns a list of all connections defined in the configuration.
 	// The connections are returned in a non-deterministic order.
 	// A common use case for this method is to ping all the connections at startup to verify that they are working.
 	List() []*sql.DB
 
+	// Ping verifies the connection with the given name by executing its HealthQuery, or by pinging it
+	// when the HealthQuery is empty. Unlike Get, it keeps working while the connections are paused.
+	// It returns an error if the connection name is not defined or if the verification fails.
+	Ping(ctx context.Context, name string) error
+
+	// PingAll verifies every connection like Ping does, for example at startup or from a health check
+	// endpoint. All the connections are verified even if some of them fail, and the errors are aggregated
+	// into a single returned error.
+	PingAll(ctx context.Context) error
+
+	// Conn returns a dedicated connection from the pool of the connection with the given name, bound to ctx.
+	// Session level settings, such as SET statements or temporary tables, stay on the returned connection,
+	// which is useful to run all the queries of a request on the same connection.
+	// The caller must call Close on the returned connection to return it to the pool.
+	// It returns an error if the connection name is not defined or if it fails to get a connection.
+	Conn(ctx context.Context, name string) (*sql.Conn, error)
+
+	// ConnectionInfo returns the configuration of the connection with the given name as it was when Open
+	// was called, so that the caller can inspect its IsMaster and IsReadOnly flags, for example to route
+	// the queries, without keeping the original Config around.
+	// It returns an error if the connection name is not defined.
+	ConnectionInfo(name string) (Connection, error)
+
+	// PoolConfig returns the connection pool settings applied to the connection with the given name,
+	// after merging the connection_pool of the connection with the default_connection_pool.
+	// Settings that are nil were not set, so the defaults of the database/sql package are in effect.
+	// It returns an error if the connection name is not defined.
+	PoolConfig(name string) (ConnectionPool, error)
+
+	// ApplyPoolConfig applies the settings of pool that are not nil to the connection with the given name,
+	// without closing the connections already open, for example to tune MaxOpenConnections live.
+	// The settings that are nil are left unchanged. PoolConfig reports the settings applied.
+	// It returns an error if the connection name is not defined.
+	ApplyPoolConfig(name string, pool ConnectionPool) error
+
+	// Stats returns the database statistics of every connection by name, including the number of
+	// connections closed by the pool limits (MaxIdleClosed, MaxIdleTimeClosed and MaxLifetimeClosed),
+	// which is useful to tune MaxIdleConnections, ConnMaxIdleTime and ConnMaxLifetime.
+	Stats() map[string]sql.DBStats
+
+	// WatchReaping samples the statistics of every connection each interval and calls fn with the number
+	// of connections closed by the pool limits since the previous sample, whenever it is at least threshold.
+	// The first sample is taken before returning, and sampling stops when ctx is done.
+	// The interval defaults to 1 minute when it is not positive, and the threshold to 1.
+	WatchReaping(ctx context.Context, interval time.Duration, threshold int64, fn func(name string, closed ReapStats))
+
+	// StartLeakDetector samples the statistics of every connection each interval and logs a warning with the
+	// logger of WithLogger when its in-use connections stay above the threshold set with WithLeakDetection for
+	// longer than its window, which usually means that some *sql.Rows, *sql.Tx or *sql.Conn are not closed.
+	// Sampling stops when ctx is done, and the interval defaults to 1 minute when it is not positive.
+	StartLeakDetector(ctx context.Context, interval time.Duration)
+
+	// Session returns a Session with read-your-writes consistency, which sends the writes to the connection
+	// with the given name and, for window after each of them, also the reads, before falling back to the
+	// read-only connections selected by GetReadOnly. It is meant to be used by a single request or caller.
+	// It returns an error if the connection name is not defined or the connections are paused.
+	Session(master string, window time.Duration) (*Session, error)
+
+	// Migrate applies on the connection with the given name, usually the master, the up-migrations of migrations
+	// that were not applied yet, in order of version, tracking the applied versions in the schema_migrations table.
+	// See Migrate for the naming of the migration files.
+	Migrate(ctx context.Context, name string, migrations fs.FS) error
+
+	// Pause makes Get, GetWithBreaker, GetBounded, GetWithRetry, GetReadOnly and the queries of the Sessions
+	// return ErrPaused, so the application stops issuing new queries, without closing the connection pools. Queries already in flight are not affected.
+	// It is useful to quiesce an instance before shutting it down, for example during blue/green deploys.
+	Pause()
+
+	// Resume restores the normal operation of Get, GetWithBreaker, GetBounded and GetReadOnly after a Pause.
+	Resume()
+
 	// Close closes all the connections to the MySQL databases.
 	// It should be called when the application is shutting down.
 	// It tries to close all the connections even if some of them fail to close.
@@ -151,13 +461,123 @@ type Connections interface {
 	Close() error
 }
 
+// ErrPaused is returned by Get, GetWithBreaker, GetBounded, GetWithRetry, GetReadOnly and the queries
+// of the Sessions while the connections are paused.
+var ErrPaused = errors.New("connections are paused")
+
 type connections struct {
-	dbs map[string]*sql.DB
+	dbs      map[string]*sql.DB
+	breakers map[string]*CircuitBreaker
+	bounded  map[string]*BoundedDB
+	retries  map[string]*RetryDB
+	readOnly *replicaBalancer
+	pools    map[string]ConnectionPool
+	infos    map[string]Connection
+	poolsMu  sync.RWMutex
+	paused   atomic.Bool
+
+	logger        logger.Logger
+	leakThreshold int
+	leakWindow    time.Duration
+}
+
+// _bestEffortPingTimeout is how long Open waits for each connection to respond in BestEffort mode.
+const _bestEffortPingTimeout = 5 * time.Second
+
+// ErrNoConnectionOpened is returned by Open when Config.BestEffort is set and none of the connections opened,
+// so unlike a *PartialOpenError there are no usable Connections.
+var ErrNoConnectionOpened = errors.New("failed to open any MySQL connection")
+
+// PartialOpenError is returned by Open along with the Connections when Config.BestEffort is set and some,
+// but not all, of the connections failed to open. The returned Connections only include the ones that opened.
+type PartialOpenError struct {
+	// Errors are the errors of the connections that failed to open by connection name.
+	Errors map[string]error
+}
+
+func (e *PartialOpenError) Error() string {
+	return fmt.Sprintf("failed to open MySQL connections: %s", e.list())
+}
+
+// list returns the errors of the connections sorted by connection name.
+func (e *PartialOpenError) list() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := make([]string, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, fmt.Sprintf("%q: %s", name, e.Errors[name]))
+	}
+
+	return strings.Join(errs, ", ")
+}
+
+// Option configures the optional behavior of Open.
+type Option func(*options)
+
+type options struct {
+	logger         logger.Logger
+	driverWrappers []DriverWrapper
+	strictDSN      bool
+	leakThreshold  int
+	leakWindow     time.Duration
+}
+
+// DriverWrapper wraps the MySQL driver of the connection with the given name, for example to instrument
+// its queries. The returned driver is used to open every connection of the pool.
+type DriverWrapper func(name string, d driver.Driver) driver.Driver
+
+// WithLogger sets the logger used by Open to report how each connection was resolved: the host, schema,
+// role and pool settings, with the password redacted. Nothing is logged when no logger is provided.
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithDriverWrapper wraps the driver of every connection with wrap. It can be used several times,
+// in which case the wrappers are applied in order, so the last one is the outermost.
+// The otelmysql package uses it to trace the queries with OpenTelemetry.
+func WithDriverWrapper(wrap DriverWrapper) Option {
+	return func(o *options) {
+		o.driverWrappers = append(o.driverWrappers, wrap)
+	}
+}
+
+// WithLeakDetection sets the parameters of Connections.StartLeakDetector: a connection is reported as leaking
+// when more than threshold of its connections stay in use for longer than window. By default, a connection
+// is reported when all the connections of its pool, as limited by MaxOpenConnections, stay in use for a minute.
+func WithLeakDetection(threshold int, window time.Duration) Option {
+	return func(o *options) {
+		o.leakThreshold = threshold
+		o.leakWindow = window
+	}
+}
+
+// WithStrictDSN makes Open fail when two connections resolve to the same effective DSN, that is the same
+// host, schema, user and parameters, which usually comes from a copy-pasted connection that doubles the pool
+// size by accident. Without this option, duplicated DSNs are only reported as a warning through the logger.
+// The check is skipped when the DSN is set in the config, since every connection shares it by design.
+func WithStrictDSN() Option {
+	return func(o *options) {
+		o.strictDSN = true
+	}
 }
 
 // Open opens one or more connections to a MySQL database.
 // It returns an error if the configuration is invalid or if it fails to open any of the connections.
-func Open(config Config) (Connections, error) {
+// When Config.BestEffort is set and only some of the connections fail, it returns the Connections that
+// opened along with a *PartialOpenError, which the caller may treat as a warning. When all of them fail,
+// it returns an error wrapping ErrNoConnectionOpened and no Connections.
+func Open(config Config, opts ...Option) (Connections, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	if config.DSN == "" && config.Cluster == "" && config.HACluster == "" {
 		return nil, errors.New("invalid MySQL config: DSN, Cluster and HACluster are empty")
 	}
@@ -186,53 +606,190 @@ func Open(config Config) (Connections, error) {
 		return nil, err
 	}
 
+	// The connections are validated before opening any of them, so an invalid connection doesn't leak
+	// the pools of the connections opened before it.
+	for _, connectionConfig := range config.Connections {
+		if err := validateConnection(config, connectionConfig); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validateDriver(getDriverName()); err != nil {
+		return nil, err
+	}
+
 	// For each connection defined in the configuration create a connection pool.
 	dbs := make(map[string]*sql.DB)
+	breakers := make(map[string]*CircuitBreaker)
+	bounded := make(map[string]*BoundedDB)
+	retries := make(map[string]*RetryDB)
+	readOnly := &replicaBalancer{}
+	pools := make(map[string]ConnectionPool)
+	dsns := make(map[string]string)
+	infos := make(map[string]Connection)
+	failed := make(map[string]error)
 	for _, connectionConfig := range config.Connections {
 		var db *sql.DB
 		var err error
 
-		if config.DSN == "" && (!connectionConfig.IsMaster && !connectionConfig.IsReadOnly) {
-			return nil, fmt.Errorf("invalid MySQL config: cannot write to a replica: connection %q", connectionConfig.Name)
+		// A read-only connection to the master is valid, for example to read data right after writing it,
+		// but it is reported so that it is not mistaken for a misconfigured replica.
+		if config.DSN == "" && connectionConfig.IsMaster && connectionConfig.IsReadOnly && o.logger != nil {
+			o.logger.Infof("MySQL connection %q is a read-only connection to the master", connectionConfig.Name)
 		}
 
+		var dsn string
+		var e endpoint
 		if config.DSN != "" {
-			db, err = openDSN(config.DSN)
+			dsn = config.DSN
+			if config.EnsureParseTime {
+				dsn = ensureDSNParseTime(dsn)
+			}
 		} else if config.Cluster != "" {
-			db, err = openMySQL(config.Cluster, config.Schema, connectionConfig)
+			e = mysqlEndpoint(config.Cluster, config.Schema, connectionConfig)
+			dsn = buildDSN(e, config.Schema, connectionConfig)
 		} else if config.HACluster != "" {
-			db, err = openMySQLHA(config.HACluster, config.Schema, connectionConfig)
+			e = mysqlHAEndpoint(config.HACluster, config.Schema, connectionConfig)
+			dsn = buildDSN(e, config.Schema, connectionConfig)
 		}
 
-		if err != nil {
-			return nil, err
+		if config.DSN == "" {
+			redacted := redactDSN(dsn)
+			if previous, ok := dsns[redacted]; ok {
+				if o.strictDSN {
+					closeAll(dbs)
+					return nil, fmt.Errorf("invalid MySQL config: connections %q and %q resolve to the same DSN %s",
+						previous, connectionConfig.Name, redacted)
+				}
+				if o.logger != nil {
+					o.logger.Warningf("MySQL connections %q and %q resolve to the same DSN %s",
+						previous, connectionConfig.Name, redacted)
+				}
+			} else {
+				dsns[redacted] = connectionConfig.Name
+			}
 		}
 
-		// Set the connection pool parameters if they are defined. Otherwise, use the default values
-		// defined by the database/sql package which are not necessarily the default zero values.
-		// For example, MaxIdleConnections is 2 by default.
-		if connectionConfig.ConnectionPool.ConnMaxLifetime != nil {
-			db.SetConnMaxLifetime(time.Duration(*connectionConfig.ConnectionPool.ConnMaxLifetime))
+		db, err = openDSN(connectionConfig.Name, dsn, connectionConfig.InitStatements, o.driverWrappers)
+		if err == nil && config.BestEffort {
+			if err = pingWithTimeout(db, _bestEffortPingTimeout); err != nil {
+				_ = db.Close()
+			}
 		}
+		if err != nil {
+			if !config.BestEffort {
+				closeAll(dbs)
+				return nil, err
+			}
 
-		if connectionConfig.ConnectionPool.MaxIdleConnections != nil {
-			db.SetMaxIdleConns(*connectionConfig.ConnectionPool.MaxIdleConnections)
+			failed[connectionConfig.Name] = err
+			if o.logger != nil {
+				o.logger.Warningf("MySQL connection %q failed to open, skipping it: %s", connectionConfig.Name, err)
+			}
+			continue
 		}
 
-		if connectionConfig.ConnectionPool.MaxOpenConnections != nil {
-			db.SetMaxOpenConns(*connectionConfig.ConnectionPool.MaxOpenConnections)
+		// Set the connection pool parameters if they are defined, either in the connection or in the
+		// default connection pool. Otherwise, use the default values defined by the database/sql package
+		// which are not necessarily the default zero values. For example, MaxIdleConnections is 2 by default.
+		pool := connectionConfig.ConnectionPool.withDefaults(config.DefaultConnectionPool)
+		if pool.MaxIdleConnections == nil {
+			if n := defaultMaxIdleConns.Load(); n != nil {
+				maxIdle := *n
+				pool.MaxIdleConnections = &maxIdle
+			}
 		}
+		applyPool(db, pool)
 
-		if connectionConfig.ConnectionPool.ConnMaxIdleTime != nil {
-			db.SetConnMaxIdleTime(time.Duration(*connectionConfig.ConnectionPool.ConnMaxIdleTime))
+		if o.logger != nil {
+			if config.DSN != "" {
+				o.logger.Infof("MySQL connection %q opened | dsn: %s | pool: %s",
+					connectionConfig.Name, redactDSN(dsn), pool)
+			} else {
+				o.logger.Infof("MySQL connection %q opened | host: %s | schema: %s | role: %s | pool: %s",
+					connectionConfig.Name, orUnset(e.host), config.Schema, e.role, pool)
+			}
 		}
 
 		dbs[connectionConfig.Name] = db
+		pools[connectionConfig.Name] = pool
+		infos[connectionConfig.Name] = connectionConfig.clone()
+		breakers[connectionConfig.Name] = newCircuitBreaker(connectionConfig.Name, db, connectionConfig.CircuitBreaker)
+		bounded[connectionConfig.Name] = newBoundedDB(connectionConfig.Name, db, connectionConfig.AcquireTimeout)
+		retries[connectionConfig.Name] = newRetryDB(db, connectionConfig.Retry)
+		if connectionConfig.IsReadOnly {
+			readOnly.add(db, connectionConfig.Weight)
+		}
+	}
+
+	if len(failed) > 0 && len(dbs) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoConnectionOpened, (&PartialOpenError{Errors: failed}).list())
 	}
 
-	return &connections{
-		dbs: dbs,
-	}, nil
+	c := &connections{
+		dbs:      dbs,
+		breakers: breakers,
+		bounded:  bounded,
+		retries:  retries,
+		readOnly: readOnly,
+		pools:    pools,
+		infos:    infos,
+
+		logger:        o.logger,
+		leakThreshold: o.leakThreshold,
+		leakWindow:    o.leakWindow,
+	}
+
+	if len(failed) > 0 {
+		return c, &PartialOpenError{Errors: failed}
+	}
+
+	return c, nil
+}
+
+// pingWithTimeout verifies that db responds within timeout.
+func pingWithTimeout(db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return db.PingContext(ctx)
+}
+
+// validateConnection validates the configuration of a single connection.
+func validateConnection(config Config, c Connection) error {
+	if config.DSN == "" && (!c.IsMaster && !c.IsReadOnly) {
+		return fmt.Errorf("invalid MySQL config: cannot write to a replica: connection %q has both is_master "+
+			"and is_read_only set to false, set is_master to true to write to the master or is_read_only to true "+
+			"to read from a replica", c.Name)
+	}
+
+	if c.Weight < 0 {
+		return fmt.Errorf("invalid MySQL config: weight must not be negative: connection %q", c.Name)
+	}
+
+	if c.Parameters != "" && len(c.ParametersMap) > 0 {
+		return fmt.Errorf("invalid MySQL config: parameters is mutually exclusive with parameters_map: "+
+			"connection %q", c.Name)
+	}
+
+	if c.DefaultIsolation != "" && !isIsolationLevel(c.DefaultIsolation) {
+		return fmt.Errorf("invalid MySQL config: default_isolation must be one of %s: connection %q has %q",
+			strings.Join(_isolationLevels, ", "), c.Name, c.DefaultIsolation)
+	}
+
+	if charset, ok := unsafeInterpolationCharset(c); ok {
+		return fmt.Errorf("invalid MySQL config: interpolate_params is incompatible with the %s charset: "+
+			"connection %q", charset, c.Name)
+	}
+
+	return nil
+}
+
+// closeAll closes the given connection pools, ignoring the errors.
+func closeAll(dbs map[string]*sql.DB) {
+	for _, db := range dbs {
+		_ = db.Close()
+	}
 }
 
 // validateDuplicateNames validates that there are no duplicated connection names.
@@ -247,88 +804,233 @@ func validateDuplicateNames(connections []Connection) error {
 	return nil
 }
 
-func openMySQL(cluster, schema string, config Connection) (*sql.DB, error) {
-	var host string
-	var username string
-	var password string
+// registeredDrivers returns the names of the registered database/sql drivers.
+var registeredDrivers = sql.Drivers //nolint:gochecknoglobals
+
+// mysqlDriverName is the name of the driver registered by github.com/go-sql-driver/mysql, which this package
+// imports. The tests replace it with the name of a mock driver.
+var mysqlDriverName = "mysql" //nolint:gochecknoglobals
+
+// validateDriver validates that the driver with the given name is registered, since otherwise
+// the connections would only fail, with a cryptic error, when they are first used.
+func validateDriver(name string) error {
+	for _, registered := range registeredDrivers() {
+		if registered == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s driver not registered; add a blank import of github.com/go-sql-driver/mysql", name)
+}
+
+// endpoint is the resolved location and credentials of a connection to a MySQL cluster.
+type endpoint struct {
+	host     string
+	role     string
+	username string
+	password string
+}
+
+// mysqlEndpoint resolves the endpoint of a connection to a MySQL cluster from the environment variables.
+func mysqlEndpoint(cluster, schema string, config Connection) endpoint {
+	var e endpoint
 
 	clusterInUpperCase := strings.ToUpper(cluster)
 	schemaInUpperCase := strings.ToUpper(schema)
 
 	if config.IsMaster {
-		host = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_ENDPOINT",
+		e.host = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_ENDPOINT",
 			clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
 	} else {
-		host = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_LOCAL_REPLICA_ENDPOINT",
+		e.host = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_LOCAL_REPLICA_ENDPOINT",
 			clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
 	}
 
 	if config.IsReadOnly {
-		username = fmt.Sprintf("%s_RPROD", schema)
-		password = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_RPROD",
-			clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.role = "RPROD"
 	} else {
-		username = fmt.Sprintf("%s_WPROD", schema)
-		password = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_WPROD",
-			clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.role = "WPROD"
 	}
 
-	// dsn has the following format: "username:password@tcp(host:port)/schema?parameters"
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s", username, password, host, schema)
-	if config.Parameters != "" {
-		dsn = fmt.Sprintf("%s?%s", dsn, config.Parameters)
-	}
+	e.username = fmt.Sprintf("%s_%s", schema, e.role)
+	e.password = os.Getenv(fmt.Sprintf("DB_MYSQL_%s_%s_%s_%s",
+		clusterInUpperCase, schemaInUpperCase, schemaInUpperCase, e.role))
+	e.host = withPort(e.host, config.Port)
 
-	return openDSN(dsn)
+	return e
 }
 
-func openMySQLHA(cluster, schema string, config Connection) (*sql.DB, error) {
-	var host string
-	var username string
-	var password string
+// mysqlHAEndpoint resolves the endpoint of a connection to a HA MySQL cluster from the environment variables.
+func mysqlHAEndpoint(cluster, schema string, config Connection) endpoint {
+	var e endpoint
 
 	clusterInUpperCase := strings.ToUpper(cluster)
 	schemaInUpperCase := strings.ToUpper(schema)
 
 	if config.IsMaster {
-		host = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_WR_ENDPOINT", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.host = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_WR_ENDPOINT", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
 	} else {
-		host = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_RO_ENDPOINT", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.host = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_RO_ENDPOINT", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
 	}
 
 	if config.IsReadOnly {
-		username = fmt.Sprintf("%s_RPROD", schema)
-		password = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_RPROD", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.role = "RPROD"
 	} else {
-		username = fmt.Sprintf("%s_WPROD", schema)
-		password = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_WPROD", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase))
+		e.role = "WPROD"
+	}
+
+	e.username = fmt.Sprintf("%s_%s", schema, e.role)
+	e.password = os.Getenv(fmt.Sprintf("DB_HA_MYSQL_%s_%s_%s_%s", clusterInUpperCase, schemaInUpperCase, schemaInUpperCase, e.role))
+	e.host = withPort(e.host, config.Port)
+
+	return e
+}
+
+// withPort returns host with port appended when port is defined and host doesn't already include a port.
+func withPort(host string, port int) string {
+	if host == "" || port == 0 {
+		return host
+	}
+
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// _defaultMySQLPort is the port the driver uses when the address of the server doesn't have one.
+const _defaultMySQLPort = "3306"
+
+// ipv6WithPort returns the IPv6 address host, such as ::1 or [::1], joined with the default MySQL port when it
+// doesn't have one, since the driver only adds the port to the hosts it can't split, bracketing them again.
+// The hosts with a port, such as [::1]:3306, and the IPv4 addresses and host names are kept as is.
+func ipv6WithPort(host string) string {
+	ip := strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if !strings.Contains(ip, ":") || net.ParseIP(ip) == nil {
+		return host
+	}
+	return net.JoinHostPort(ip, _defaultMySQLPort)
+}
+
+// buildDSN builds the DSN of a connection to a MySQL cluster.
+// The dsn has the following format: "username:password@tcp(host:port)/schema?parameters"
+func buildDSN(e endpoint, schema string, config Connection) string {
+	parameters := config.Parameters
+	if len(config.ParametersMap) > 0 {
+		parameters = encodeParameters(config.ParametersMap)
+	}
+
+	parameters = withParameters(parameters, mysqlDialect{}.parameters(config))
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", e.username, e.password, ipv6WithPort(e.host), schema,
+		ensureParseTime(parameters))
+}
+
+// ensureParseTime appends parseTime=true to the parameters unless the parseTime parameter is already set.
+func ensureParseTime(parameters string) string {
+	if parameters == "" {
+		return "parseTime=true"
+	}
+
+	for _, parameter := range strings.Split(parameters, "&") {
+		if key, _, _ := strings.Cut(parameter, "="); key == "parseTime" {
+			return parameters
+		}
+	}
+
+	return parameters + "&parseTime=true"
+}
+
+// ensureDSNParseTime applies ensureParseTime to the parameters of the given dsn.
+func ensureDSNParseTime(dsn string) string {
+	// The parameters are placed after the first question mark that follows the last slash,
+	// since the password may contain both characters.
+	slash := strings.LastIndex(dsn, "/")
+	base, parameters, _ := strings.Cut(dsn[slash+1:], "?")
+
+	return dsn[:slash+1] + base + "?" + ensureParseTime(parameters)
+}
+
+// redactDSN returns the dsn with the password replaced by asterisks.
+func redactDSN(dsn string) string {
+	// The credentials are placed before the last at sign that precedes the last slash,
+	// since the password may contain both characters.
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return dsn
 	}
 
-	// dsn has the following format: "username:password@tcp(host:port)/schema?parameters"
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s", username, password, host, schema)
-	if config.Parameters != "" {
-		dsn = fmt.Sprintf("%s?%s", dsn, config.Parameters)
+	at := strings.LastIndex(dsn[:slash], "@")
+	if at < 0 {
+		return dsn
 	}
 
-	return openDSN(dsn)
+	username, _, hasPassword := strings.Cut(dsn[:at], ":")
+	if !hasPassword {
+		return dsn
+	}
+
+	return username + ":***" + dsn[at:]
+}
+
+// orUnset returns value or <unset> if value is empty.
+func orUnset(value string) string {
+	if value == "" {
+		return "<unset>"
+	}
+	return value
 }
 
 // openDSN opens a connection to a MySQL database using the given DSN.
-func openDSN(dsn string) (*sql.DB, error) {
+// The driver is wrapped by every wrapper in order, and the initStatements, if any, are executed
+// on every new connection of the pool.
+func openDSN(name, dsn string, initStatements []string, wrappers []DriverWrapper) (*sql.DB, error) {
 	db, err := sql.Open(getDriverName(), dsn)
 	if err != nil {
 		return nil, err
 	}
-	return db, nil
+
+	if len(initStatements) == 0 && len(wrappers) == 0 {
+		return db, nil
+	}
+
+	// sql.Open doesn't connect to the database, so the pool is only used to get the registered driver
+	// and open a new pool with a custom connector. The connector of the new pool is the one
+	// reporting the errors on Close, so the error of closing this one is ignored.
+	d := db.Driver()
+	_ = db.Close()
+
+	for _, wrap := range wrappers {
+		d = wrap(name, d)
+	}
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: d}
+	if dc, ok := d.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(initStatements) > 0 {
+		connector = &initConnector{Connector: connector, statements: initStatements}
+	}
+
+	return sql.OpenDB(connector), nil
+}
+
+// DriverName returns the name of the database/sql driver used to open the connections, which is "nrmysql"
+// when the New Relic integration is imported and "mysql" otherwise.
+func DriverName() string {
+	return getDriverName()
 }
 
 // getDriverName returns the driver name to use for the MySQL connection.
 // It returns "nrmysql" if the driver is available, otherwise it returns "mysql".
-// To include the "nrmysql" driver you need to import the nrmysql package.
+// To include the "nrmysql" driver you need to import the nrmysql package, or the nrmysqlreg module,
+// which keeps the New Relic agent out of the dependencies of this module.
 // For example:
 //
 //	import (
-//		_ "github.com/newrelic/go-agent/v3/integrations/nrmysql"
+//		_ "github.com/JhonX2011/GOWebApplication/database/mysqlconnect/nrmysqlreg"
 //	)
 func getDriverName() string {
 	for _, name := range sql.Drivers() {
@@ -336,11 +1038,15 @@ func getDriverName() string {
 			return "nrmysql"
 		}
 	}
-	return "mysql"
+	return mysqlDriverName
 }
 
-// Get implements the Connection interface.
+// Get implements the Connections interface.
 func (c *connections) Get(name string) (*sql.DB, error) {
+	if c.paused.Load() {
+		return nil, ErrPaused
+	}
+
 	connection, ok := c.dbs[name]
 	if !ok {
 		return nil, fmt.Errorf("unknown connection name %s", name)
@@ -349,12 +1055,177 @@ func (c *connections) Get(name string) (*sql.DB, error) {
 	return connection, nil
 }
 
-// List implements the Connection interface.
+// Has implements the Connections interface.
+func (c *connections) Has(name string) bool {
+	_, ok := c.dbs[name]
+	return ok
+}
+
+// GetWithBreaker implements the Connections interface.
+func (c *connections) GetWithBreaker(name string) (*CircuitBreaker, error) {
+	if c.paused.Load() {
+		return nil, ErrPaused
+	}
+
+	breaker, ok := c.breakers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection name %s", name)
+	}
+
+	return breaker, nil
+}
+
+// Conn implements the Connections interface.
+func (c *connections) Conn(ctx context.Context, name string) (*sql.Conn, error) {
+	db, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection %q: %w", name, err)
+	}
+
+	return conn, nil
+}
+
+// ConnectionInfo implements the Connections interface.
+func (c *connections) ConnectionInfo(name string) (Connection, error) {
+	info, ok := c.infos[name]
+	if !ok {
+		return Connection{}, fmt.Errorf("unknown connection name %s", name)
+	}
+
+	return info.clone(), nil
+}
+
+// PoolConfig implements the Connections interface.
+func (c *connections) PoolConfig(name string) (ConnectionPool, error) {
+	c.poolsMu.RLock()
+	defer c.poolsMu.RUnlock()
+
+	pool, ok := c.pools[name]
+	if !ok {
+		return ConnectionPool{}, fmt.Errorf("unknown connection name %s", name)
+	}
+
+	return pool, nil
+}
+
+// ApplyPoolConfig implements the Connections interface.
+func (c *connections) ApplyPoolConfig(name string, pool ConnectionPool) error {
+	db, ok := c.dbs[name]
+	if !ok {
+		return fmt.Errorf("unknown connection name %s", name)
+	}
+
+	c.poolsMu.Lock()
+	defer c.poolsMu.Unlock()
+
+	current := c.pools[name]
+	applyPool(db, pool)
+	c.pools[name] = pool.withDefaults(&current)
+
+	return nil
+}
+
+// GetBounded implements the Connections interface.
+func (c *connections) GetBounded(name string) (*BoundedDB, error) {
+	if c.paused.Load() {
+		return nil, ErrPaused
+	}
+
+	bounded, ok := c.bounded[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection name %s", name)
+	}
+
+	return bounded, nil
+}
+
+// GetReadOnly implements the Connections interface.
+func (c *connections) GetReadOnly() (*sql.DB, error) {
+	if c.paused.Load() {
+		return nil, ErrPaused
+	}
+
+	db := c.readOnly.next()
+	if db == nil {
+		return nil, ErrNoReadOnlyConnection
+	}
+
+	return db, nil
+}
+
+// List implements the Connections interface.
 func (c *connections) List() []*sql.DB {
 	return maps.Values(c.dbs)
 }
 
-// Close implements the Connection interface.
+// Ping implements the Connections interface.
+func (c *connections) Ping(ctx context.Context, name string) error {
+	db, ok := c.dbs[name]
+	if !ok {
+		return fmt.Errorf("unknown connection name %s", name)
+	}
+
+	if err := healthCheck(ctx, db, c.infos[name].HealthQuery); err != nil {
+		return fmt.Errorf("failed to ping connection %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// PingAll implements the Connections interface.
+func (c *connections) PingAll(ctx context.Context) error {
+	names := maps.Keys(c.dbs)
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		if err := c.Ping(ctx, name); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to ping MySQL connections: %s", strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+// healthCheck executes query on db discarding its rows, or pings db when query is empty.
+func healthCheck(ctx context.Context, db *sql.DB, query string) error {
+	if query == "" {
+		return db.PingContext(ctx)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	// The rows are only read to surface the errors reported while streaming them.
+	for rows.Next() {
+	}
+
+	return rows.Err()
+}
+
+// Pause implements the Connections interface.
+func (c *connections) Pause() {
+	c.paused.Store(true)
+}
+
+// Resume implements the Connections interface.
+func (c *connections) Resume() {
+	c.paused.Store(false)
+}
+
+// Close implements the Connections interface.
 func (c *connections) Close() error {
 	// Put the keys of the map in a sorted slice so that we close the connections in a deterministic order.
 	// Specially useful for tests.
@@ -1,24 +1,34 @@
 package mysqlconnect
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
 	"github.com/stretchr/testify/require"
 )
 
 var mockDriver = mocks.MysqlDriverMock{}
 
 func init() {
-	sql.Register("mysql", &mockDriver)
+	sql.Register("mysqlconnect_mock", &mockDriver)
+	mysqlDriverName = "mysqlconnect_mock"
 }
 
 func TestOpen_ConfigPreconditions(t *testing.T) {
+	interpolateParams := true
+
 	testCases := []struct {
 		name       string
 		config     Config
@@ -107,7 +117,56 @@ func TestOpen_ConfigPreconditions(t *testing.T) {
 					},
 				},
 			},
-			errMessage: "invalid MySQL config: cannot write to a replica: connection \"foo\"",
+			errMessage: "invalid MySQL config: cannot write to a replica: connection \"foo\" has both is_master and " +
+				"is_read_only set to false, set is_master to true to write to the master or is_read_only to true to read from a replica",
+		},
+		{
+			name: "parameters and parameters map are set",
+			config: Config{
+				Cluster: "DB_MYSQL_DESAENV08_FOO",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:          "foo",
+						IsMaster:      true,
+						Parameters:    "charset=utf8mb4",
+						ParametersMap: map[string]string{"charset": "utf8mb4"},
+					},
+				},
+			},
+			errMessage: "invalid MySQL config: parameters is mutually exclusive with parameters_map: connection \"foo\"",
+		},
+		{
+			name: "unknown default isolation",
+			config: Config{
+				Cluster: "DB_MYSQL_DESAENV08_FOO",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:             "foo",
+						IsMaster:         true,
+						DefaultIsolation: "READ COMMITTED",
+					},
+				},
+			},
+			errMessage: "invalid MySQL config: default_isolation must be one of READ-UNCOMMITTED, READ-COMMITTED, " +
+				"REPEATABLE-READ, SERIALIZABLE: connection \"foo\" has \"READ COMMITTED\"",
+		},
+		{
+			name: "interpolate params with an unsafe charset",
+			config: Config{
+				Cluster: "DB_MYSQL_DESAENV08_FOO",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:              "foo",
+						IsMaster:          true,
+						Parameters:        "charset=gbk",
+						InterpolateParams: &interpolateParams,
+					},
+				},
+			},
+			errMessage: `invalid MySQL config: interpolate_params is incompatible with the gbk charset: connection "foo"`,
 		},
 	}
 
@@ -132,6 +191,44 @@ func TestDuration_UnmarshalJSON_InvalidDuration(t *testing.T) {
 	require.EqualError(t, err, "invalid duration: \"foo\"")
 }
 
+func TestDuration_UnmarshalJSON_NotAString(t *testing.T) {
+	testCases := []struct {
+		name       string
+		data       string
+		errMessage string
+	}{
+		{
+			name:       "number",
+			data:       "600",
+			errMessage: "duration must be a string, got number",
+		},
+		{
+			name:       "boolean",
+			data:       "true",
+			errMessage: "duration must be a string, got bool",
+		},
+		{
+			name:       "object",
+			data:       `{"seconds":600}`,
+			errMessage: "duration must be a string, got object",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var duration Duration
+			err := duration.UnmarshalJSON([]byte(tc.data))
+			require.EqualError(t, err, tc.errMessage)
+		})
+	}
+}
+
+func TestConfigAsJSON_NumericDuration(t *testing.T) {
+	var config Config
+	err := json.Unmarshal([]byte(`{"connections":[{"name":"foo","connection_pool":{"conn_max_lifetime":600}}]}`), &config)
+	require.EqualError(t, err, "duration must be a string, got number")
+}
+
 func TestDuration_UnmarshalJSON(t *testing.T) {
 	var duration Duration
 	err := duration.UnmarshalJSON([]byte("\"100ms\""))
@@ -252,6 +349,114 @@ func TestOpen(t *testing.T) {
 			},
 			expectedDSN: "bar_RPROD:password@tcp(localhost:3306)/bar?timeout=100ms&readTimeout=100ms&writeTimeout=100ms&parseTime=true",
 		},
+		{
+			name: "fury mysql endpoint with host only, port is appended",
+			config: Config{
+				Cluster: "desaenv08",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:     "foo",
+						IsMaster: true,
+						Port:     3307,
+					},
+				},
+			},
+			expectedDSN: "bar_WPROD:password@tcp(localhost:3307)/bar?parseTime=true",
+			setEnvVarFunc: func(t *testing.T) {
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "localhost")
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_WPROD", "password")
+			},
+		},
+		{
+			name: "fury mysql ha endpoint with host and port, port is kept",
+			config: Config{
+				HACluster: "desaenv08",
+				Schema:    "bar",
+				Connections: []Connection{
+					{
+						Name:       "foo",
+						IsReadOnly: true,
+						Port:       3307,
+					},
+				},
+			},
+			expectedDSN: "bar_RPROD:password@tcp(localhost:3306)/bar?parseTime=true",
+			setEnvVarFunc: func(t *testing.T) {
+				t.Setenv("DB_HA_MYSQL_DESAENV08_BAR_BAR_RO_ENDPOINT", "localhost:3306")
+				t.Setenv("DB_HA_MYSQL_DESAENV08_BAR_BAR_RPROD", "password")
+			},
+		},
+		{
+			name: "use DSN, parseTime is ensured",
+			config: Config{
+				DSN:             "root:password@tcp(localhost:3306)/foo?timeout=100ms",
+				EnsureParseTime: true,
+				Connections: []Connection{
+					{
+						Name: "foo",
+					},
+				},
+			},
+			expectedDSN:   "root:password@tcp(localhost:3306)/foo?timeout=100ms&parseTime=true",
+			setEnvVarFunc: func(t *testing.T) {},
+		},
+		{
+			name: "fury mysql without parameters, parseTime is added",
+			config: Config{
+				Cluster: "desaenv08",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:     "foo",
+						IsMaster: true,
+					},
+				},
+			},
+			expectedDSN: "bar_WPROD:password@tcp(localhost:3306)/bar?parseTime=true",
+			setEnvVarFunc: func(t *testing.T) {
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "localhost:3306")
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_WPROD", "password")
+			},
+		},
+		{
+			name: "fury mysql ha with parseTime explicitly disabled",
+			config: Config{
+				HACluster: "desaenv08",
+				Schema:    "bar",
+				Connections: []Connection{
+					{
+						Name:       "foo",
+						IsMaster:   true,
+						Parameters: "timeout=100ms&parseTime=false",
+					},
+				},
+			},
+			expectedDSN: "bar_WPROD:password@tcp(localhost:3306)/bar?timeout=100ms&parseTime=false",
+			setEnvVarFunc: func(t *testing.T) {
+				t.Setenv("DB_HA_MYSQL_DESAENV08_BAR_BAR_WR_ENDPOINT", "localhost:3306")
+				t.Setenv("DB_HA_MYSQL_DESAENV08_BAR_BAR_WPROD", "password")
+			},
+		},
+		{
+			name: "fury mysql replica with default isolation",
+			config: Config{
+				Cluster: "desaenv08",
+				Schema:  "bar",
+				Connections: []Connection{
+					{
+						Name:             "foo",
+						IsReadOnly:       true,
+						DefaultIsolation: "READ-COMMITTED",
+					},
+				},
+			},
+			expectedDSN: "bar_RPROD:password@tcp(localhost:3306)/bar?transaction_isolation=%27READ-COMMITTED%27&parseTime=true",
+			setEnvVarFunc: func(t *testing.T) {
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_LOCAL_REPLICA_ENDPOINT", "localhost:3306")
+				t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_RPROD", "password")
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -278,41 +483,456 @@ func TestOpen(t *testing.T) {
 	}
 }
 
-func TestConnections_List(t *testing.T) {
-	config := Config{
-		DSN: "root:password@tcp(localhost:3306)/foo?timeout=100ms&readTimeout=100ms&writeTimeout=100ms&parseTime=true",
+func TestConnections_List(t *testing.T) {
+	config := Config{
+		DSN: "root:password@tcp(localhost:3306)/foo?timeout=100ms&readTimeout=100ms&writeTimeout=100ms&parseTime=true",
+		Connections: []Connection{
+			{
+				Name: "foo",
+			},
+			{
+				Name: "bar",
+			},
+			{
+				Name: "baz",
+			},
+		},
+	}
+
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{}, nil
+	}
+
+	connections, err := Open(config)
+	require.NoError(t, err)
+
+	dbs := connections.List()
+	require.Len(t, dbs, 3)
+
+	var count int
+	for _, db := range dbs {
+		count++
+		err = db.Ping()
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+}
+
+func TestConnections_Has(t *testing.T) {
+	config := Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name: "foo",
+			},
+			{
+				Name: "bar",
+			},
+		},
+	}
+
+	connections, err := Open(config)
+	require.NoError(t, err)
+
+	require.True(t, connections.Has("foo"))
+	require.True(t, connections.Has("bar"))
+	require.False(t, connections.Has("baz"))
+}
+
+func TestConnections_Conn(t *testing.T) {
+	var opened int
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		opened++
+		return &mocks.DriverConnMock{CloseFunc: func() error { return nil }}, nil
+	}
+
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	conn, err := connections.Conn(context.Background(), "foo")
+	require.NoError(t, err)
+	require.Equal(t, 1, opened)
+	require.NoError(t, conn.Close())
+
+	_, err = connections.Conn(context.Background(), "bar")
+	require.EqualError(t, err, "unknown connection name bar")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = connections.Conn(ctx, "foo")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, `failed to get connection "foo"`)
+
+	connections.Pause()
+	_, err = connections.Conn(context.Background(), "foo")
+	require.ErrorIs(t, err, ErrPaused)
+}
+
+func TestConnections_PoolConfig(t *testing.T) {
+	maxOpen, maxIdle := 10, 5
+	lifetime := Duration(10 * time.Minute)
+
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		DefaultConnectionPool: &ConnectionPool{
+			ConnMaxLifetime:    &lifetime,
+			MaxOpenConnections: &maxOpen,
+		},
+		Connections: []Connection{
+			{
+				Name:           "foo",
+				ConnectionPool: ConnectionPool{MaxIdleConnections: &maxIdle},
+			},
+			{
+				Name: "bar",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	pool, err := connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Equal(t, ConnectionPool{
+		ConnMaxLifetime:    &lifetime,
+		MaxIdleConnections: &maxIdle,
+		MaxOpenConnections: &maxOpen,
+	}, pool)
+
+	pool, err = connections.PoolConfig("bar")
+	require.NoError(t, err)
+	require.Equal(t, ConnectionPool{
+		ConnMaxLifetime:    &lifetime,
+		MaxOpenConnections: &maxOpen,
+	}, pool)
+
+	_, err = connections.PoolConfig("baz")
+	require.EqualError(t, err, "unknown connection name baz")
+}
+
+func TestSetDefaultMaxIdleConns(t *testing.T) {
+	SetDefaultMaxIdleConns(1)
+	t.Cleanup(func() { SetDefaultMaxIdleConns(-1) })
+
+	maxIdle, defaultMaxIdle, maxOpen := 5, 3, 10
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name:           "foo",
+				ConnectionPool: ConnectionPool{MaxIdleConnections: &maxIdle},
+			},
+			{
+				Name:           "bar",
+				ConnectionPool: ConnectionPool{MaxOpenConnections: &maxOpen},
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	pool, err := connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Equal(t, 5, *pool.MaxIdleConnections)
+
+	pool, err = connections.PoolConfig("bar")
+	require.NoError(t, err)
+	require.Equal(t, 1, *pool.MaxIdleConnections)
+	require.Equal(t, 10, *pool.MaxOpenConnections)
+
+	// The default connection pool takes precedence over the package default.
+	connections, err = Open(Config{
+		DSN:                   "root:password@tcp(localhost:3306)/foo",
+		DefaultConnectionPool: &ConnectionPool{MaxIdleConnections: &defaultMaxIdle},
+		Connections:           []Connection{{Name: "foo"}},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	pool, err = connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Equal(t, 3, *pool.MaxIdleConnections)
+
+	// Changing the default doesn't affect the pools already opened, and a negative value unsets it.
+	SetDefaultMaxIdleConns(-1)
+	pool, err = connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Equal(t, 3, *pool.MaxIdleConnections)
+
+	connections, err = Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
+	require.NoError(t, err)
+	defer connections.Close()
+
+	pool, err = connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Nil(t, pool.MaxIdleConnections)
+}
+
+// poolSetterSpy records the pool setters invoked by applyPool.
+type poolSetterSpy struct {
+	calls []string
+}
+
+func (s *poolSetterSpy) SetConnMaxLifetime(d time.Duration) {
+	s.calls = append(s.calls, fmt.Sprintf("SetConnMaxLifetime(%s)", d))
+}
+
+func (s *poolSetterSpy) SetMaxIdleConns(n int) {
+	s.calls = append(s.calls, fmt.Sprintf("SetMaxIdleConns(%d)", n))
+}
+
+func (s *poolSetterSpy) SetMaxOpenConns(n int) {
+	s.calls = append(s.calls, fmt.Sprintf("SetMaxOpenConns(%d)", n))
+}
+
+func (s *poolSetterSpy) SetConnMaxIdleTime(d time.Duration) {
+	s.calls = append(s.calls, fmt.Sprintf("SetConnMaxIdleTime(%s)", d))
+}
+
+func TestApplyPool(t *testing.T) {
+	lifetime, idleTime := Duration(10*time.Minute), Duration(time.Minute)
+	maxIdle, maxOpen := 5, 10
+
+	testCases := []struct {
+		name     string
+		pool     ConnectionPool
+		expected []string
+	}{
+		{
+			name:     "ConnMaxLifetime",
+			pool:     ConnectionPool{ConnMaxLifetime: &lifetime},
+			expected: []string{"SetConnMaxLifetime(10m0s)"},
+		},
+		{
+			name:     "MaxIdleConnections",
+			pool:     ConnectionPool{MaxIdleConnections: &maxIdle},
+			expected: []string{"SetMaxIdleConns(5)"},
+		},
+		{
+			name:     "MaxOpenConnections",
+			pool:     ConnectionPool{MaxOpenConnections: &maxOpen},
+			expected: []string{"SetMaxOpenConns(10)"},
+		},
+		{
+			name:     "ConnMaxIdleTime",
+			pool:     ConnectionPool{ConnMaxIdleTime: &idleTime},
+			expected: []string{"SetConnMaxIdleTime(1m0s)"},
+		},
+		{
+			name: "all",
+			pool: ConnectionPool{
+				ConnMaxLifetime:    &lifetime,
+				MaxIdleConnections: &maxIdle,
+				MaxOpenConnections: &maxOpen,
+				ConnMaxIdleTime:    &idleTime,
+			},
+			expected: []string{
+				"SetConnMaxLifetime(10m0s)",
+				"SetMaxIdleConns(5)",
+				"SetMaxOpenConns(10)",
+				"SetConnMaxIdleTime(1m0s)",
+			},
+		},
+		{
+			name: "none",
+			pool: ConnectionPool{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			spy := &poolSetterSpy{}
+			applyPool(spy, tc.pool)
+			require.Equal(t, tc.expected, spy.calls)
+		})
+	}
+}
+
+func TestConnections_ConnectionInfo(t *testing.T) {
+	config := Config{
+		Cluster: "desaenv08",
+		Schema:  "bar",
+		Connections: []Connection{
+			{Name: "master_rw", IsMaster: true, InitStatements: []string{"SET SESSION sql_mode='STRICT_ALL_TABLES'"}},
+			{Name: "replica_ro", IsReadOnly: true, Weight: 3},
+			{Name: "master_ro", IsMaster: true, IsReadOnly: true},
+		},
+	}
+
+	connections, err := Open(config)
+	require.NoError(t, err)
+
+	for _, expected := range config.Connections {
+		info, err := connections.ConnectionInfo(expected.Name)
+		require.NoError(t, err)
+		require.Equal(t, expected, info)
+		require.Equal(t, expected.IsMaster, info.IsMaster)
+		require.Equal(t, expected.IsReadOnly, info.IsReadOnly)
+	}
+
+	// The returned configuration is a copy that doesn't change what the next callers see.
+	info, err := connections.ConnectionInfo("master_rw")
+	require.NoError(t, err)
+	info.InitStatements[0] = "SET SESSION sql_mode=''"
+	config.Connections[0].InitStatements[0] = "SET SESSION sql_mode=''"
+
+	info, err = connections.ConnectionInfo("master_rw")
+	require.NoError(t, err)
+	require.Equal(t, []string{"SET SESSION sql_mode='STRICT_ALL_TABLES'"}, info.InitStatements)
+
+	_, err = connections.ConnectionInfo("baz")
+	require.EqualError(t, err, "unknown connection name baz")
+}
+
+func TestConnections_Ping(t *testing.T) {
+	var queries []string
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		if strings.Contains(name, "down") {
+			return nil, errors.New("connection refused")
+		}
+
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				queries = append(queries, query)
+				if strings.Contains(query, "missing_table") {
+					return nil, errors.New("Error 1146: Table 'foo.missing_table' doesn't exist")
+				}
+				return &mocks.DriverStmtMock{
+					QueryFunc: func(args []driver.Value) (driver.Rows, error) {
+						return &mocks.DriverRowsMock{ColumnsValue: []string{"1"}, Values: [][]driver.Value{{int64(1)}}}, nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{Name: "health_query", HealthQuery: "SELECT 1 FROM critical_table LIMIT 1"},
+			{Name: "missing_table", HealthQuery: "SELECT 1 FROM missing_table LIMIT 1"},
+			{Name: "ping"},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, connections.Ping(ctx, "health_query"))
+	require.Equal(t, []string{"SELECT 1 FROM critical_table LIMIT 1"}, queries)
+
+	err = connections.Ping(ctx, "missing_table")
+	require.EqualError(t, err, `failed to ping connection "missing_table": Error 1146: Table 'foo.missing_table' doesn't exist`)
+
+	// Without a health query the connection is pinged, which doesn't execute any query.
+	queries = nil
+	require.NoError(t, connections.Ping(ctx, "ping"))
+	require.Empty(t, queries)
+
+	// Ping keeps working while the connections are paused.
+	connections.Pause()
+	require.NoError(t, connections.Ping(ctx, "health_query"))
+	connections.Resume()
+
+	err = connections.PingAll(ctx)
+	require.EqualError(t, err, `failed to ping MySQL connections: failed to ping connection "missing_table": `+
+		`Error 1146: Table 'foo.missing_table' doesn't exist`)
+
+	err = connections.Ping(ctx, "baz")
+	require.EqualError(t, err, "unknown connection name baz")
+}
+
+func TestConnections_PingAll_Down(t *testing.T) {
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(down:3306)/foo",
+		Connections: []Connection{{Name: "foo"}, {Name: "bar", HealthQuery: "SELECT 1"}},
+	})
+	require.NoError(t, err)
+
+	err = connections.PingAll(context.Background())
+	require.EqualError(t, err, `failed to ping MySQL connections: failed to ping connection "bar": connection refused, `+
+		`failed to ping connection "foo": connection refused`)
+}
+
+func TestConnections_ApplyPoolConfig(t *testing.T) {
+	maxOpen, maxIdle := 10, 5
+
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
 		Connections: []Connection{
 			{
 				Name: "foo",
-			},
-			{
-				Name: "bar",
-			},
-			{
-				Name: "baz",
+				ConnectionPool: ConnectionPool{
+					MaxOpenConnections: &maxOpen,
+					MaxIdleConnections: &maxIdle,
+				},
 			},
 		},
-	}
+	})
+	require.NoError(t, err)
+
+	db, err := connections.Get("foo")
+	require.NoError(t, err)
+	require.Equal(t, 10, db.Stats().MaxOpenConnections)
+
+	newMaxOpen := 3
+	err = connections.ApplyPoolConfig("foo", ConnectionPool{MaxOpenConnections: &newMaxOpen})
+	require.NoError(t, err)
+	require.Equal(t, 3, db.Stats().MaxOpenConnections)
+
+	pool, err := connections.PoolConfig("foo")
+	require.NoError(t, err)
+	require.Equal(t, ConnectionPool{
+		MaxOpenConnections: &newMaxOpen,
+		MaxIdleConnections: &maxIdle,
+	}, pool)
+
+	err = connections.ApplyPoolConfig("bar", ConnectionPool{MaxOpenConnections: &newMaxOpen})
+	require.EqualError(t, err, "unknown connection name bar")
+}
 
+func TestConnections_PauseResume(t *testing.T) {
 	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
 		return &mocks.DriverConnMock{}, nil
 	}
 
-	connections, err := Open(config)
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
 	require.NoError(t, err)
 
-	dbs := connections.List()
-	require.Len(t, dbs, 3)
+	db, err := connections.Get("foo")
+	require.NoError(t, err)
 
-	var count int
-	for _, db := range dbs {
-		count++
-		err = db.Ping()
-		require.NoError(t, err)
-	}
+	connections.Pause()
+
+	_, err = connections.Get("foo")
+	require.ErrorIs(t, err, ErrPaused)
+	_, err = connections.GetWithBreaker("foo")
+	require.ErrorIs(t, err, ErrPaused)
+
+	// The pools are kept open while paused.
+	require.NoError(t, db.Ping())
 
+	connections.Resume()
+
+	_, err = connections.Get("foo")
+	require.NoError(t, err)
+	_, err = connections.GetWithBreaker("foo")
 	require.NoError(t, err)
-	require.Equal(t, 3, count)
 }
 
 func TestConnections_Close(t *testing.T) {
@@ -706,3 +1326,504 @@ func TestConfigAsJSON_DefaultConnectionPool(t *testing.T) {
 	_, err = connections.Get("4")
 	require.NoError(t, err)
 }
+
+func TestConnectionPool_WithDefaults(t *testing.T) {
+	lifetime := Duration(10 * time.Minute)
+	idleTime := Duration(1 * time.Minute)
+	defaultLifetime := Duration(20 * time.Minute)
+	defaultIdleTime := Duration(2 * time.Minute)
+	maxIdle, maxOpen := 10, 20
+	defaultMaxIdle, defaultMaxOpen := 30, 40
+
+	defaults := &ConnectionPool{
+		ConnMaxLifetime:    &defaultLifetime,
+		MaxIdleConnections: &defaultMaxIdle,
+		MaxOpenConnections: &defaultMaxOpen,
+		ConnMaxIdleTime:    &defaultIdleTime,
+	}
+
+	testCases := []struct {
+		name     string
+		pool     ConnectionPool
+		defaults *ConnectionPool
+		expected ConnectionPool
+	}{
+		{
+			name:     "no defaults",
+			pool:     ConnectionPool{MaxIdleConnections: &maxIdle},
+			defaults: nil,
+			expected: ConnectionPool{MaxIdleConnections: &maxIdle},
+		},
+		{
+			name:     "empty pool takes every default",
+			pool:     ConnectionPool{},
+			defaults: defaults,
+			expected: *defaults,
+		},
+		{
+			name: "connection values take precedence",
+			pool: ConnectionPool{
+				ConnMaxLifetime:    &lifetime,
+				MaxIdleConnections: &maxIdle,
+				MaxOpenConnections: &maxOpen,
+				ConnMaxIdleTime:    &idleTime,
+			},
+			defaults: defaults,
+			expected: ConnectionPool{
+				ConnMaxLifetime:    &lifetime,
+				MaxIdleConnections: &maxIdle,
+				MaxOpenConnections: &maxOpen,
+				ConnMaxIdleTime:    &idleTime,
+			},
+		},
+		{
+			name: "fields are merged one by one",
+			pool: ConnectionPool{
+				ConnMaxLifetime:    &lifetime,
+				MaxOpenConnections: &maxOpen,
+			},
+			defaults: defaults,
+			expected: ConnectionPool{
+				ConnMaxLifetime:    &lifetime,
+				MaxIdleConnections: &defaultMaxIdle,
+				MaxOpenConnections: &maxOpen,
+				ConnMaxIdleTime:    &defaultIdleTime,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.pool.withDefaults(tc.defaults))
+		})
+	}
+}
+
+func TestConfigAsJSON_ConfigDefaultConnectionPool(t *testing.T) {
+	configJSON := `{
+  "dsn": "root:password@tcp(localhost:3306)/foo",
+  "default_connection_pool": {
+    "conn_max_lifetime": "10m",
+    "max_open_connections": 50
+  },
+  "connections": [
+    {
+      "name": "default"
+    },
+    {
+      "name": "override",
+      "connection_pool": {
+        "max_open_connections": 5
+      }
+    }
+  ]
+}`
+	var config Config
+	err := json.Unmarshal([]byte(configJSON), &config)
+	require.NoError(t, err)
+
+	require.Equal(t, Duration(10*time.Minute), *config.DefaultConnectionPool.ConnMaxLifetime)
+	require.Equal(t, 50, *config.DefaultConnectionPool.MaxOpenConnections)
+	require.Nil(t, config.DefaultConnectionPool.MaxIdleConnections)
+	require.Nil(t, config.DefaultConnectionPool.ConnMaxIdleTime)
+
+	connections, err := Open(config)
+	require.NoError(t, err)
+
+	db, err := connections.Get("default")
+	require.NoError(t, err)
+	require.Equal(t, 50, db.Stats().MaxOpenConnections)
+
+	db, err = connections.Get("override")
+	require.NoError(t, err)
+	require.Equal(t, 5, db.Stats().MaxOpenConnections)
+}
+
+func TestEnsureParseTime(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters string
+		expected   string
+	}{
+		{
+			name:       "empty parameters",
+			parameters: "",
+			expected:   "parseTime=true",
+		},
+		{
+			name:       "parseTime missing",
+			parameters: "timeout=100ms&readTimeout=100ms",
+			expected:   "timeout=100ms&readTimeout=100ms&parseTime=true",
+		},
+		{
+			name:       "parseTime enabled",
+			parameters: "parseTime=true&timeout=100ms",
+			expected:   "parseTime=true&timeout=100ms",
+		},
+		{
+			name:       "parseTime disabled",
+			parameters: "timeout=100ms&parseTime=false",
+			expected:   "timeout=100ms&parseTime=false",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ensureParseTime(tc.parameters))
+		})
+	}
+}
+
+func TestEnsureDSNParseTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dsn      string
+		expected string
+	}{
+		{
+			name:     "without parameters",
+			dsn:      "root:password@tcp(localhost:3306)/foo",
+			expected: "root:password@tcp(localhost:3306)/foo?parseTime=true",
+		},
+		{
+			name:     "with parameters",
+			dsn:      "root:password@tcp(localhost:3306)/foo?timeout=100ms",
+			expected: "root:password@tcp(localhost:3306)/foo?timeout=100ms&parseTime=true",
+		},
+		{
+			name:     "parseTime already set",
+			dsn:      "root:password@tcp(localhost:3306)/foo?parseTime=false",
+			expected: "root:password@tcp(localhost:3306)/foo?parseTime=false",
+		},
+		{
+			name:     "password with special characters",
+			dsn:      "root:pa?ss/word@tcp(localhost:3306)/foo",
+			expected: "root:pa?ss/word@tcp(localhost:3306)/foo?parseTime=true",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ensureDSNParseTime(tc.dsn))
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql.json")
+	configJSON := `{
+  "cluster": "desaenv08",
+  "schema": "my_schema",
+  "connections": [
+    {
+      "name": "master_rw",
+      "is_master": true,
+      "connection_pool": {
+        "max_open_connections": 100
+      }
+    }
+  ]
+}`
+	require.NoError(t, os.WriteFile(path, []byte(configJSON), 0o600))
+
+	config, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	require.Equal(t, "desaenv08", config.Cluster)
+	require.Equal(t, "my_schema", config.Schema)
+	require.Len(t, config.Connections, 1)
+	require.Equal(t, "master_rw", config.Connections[0].Name)
+	require.Equal(t, true, config.Connections[0].IsMaster)
+	require.Equal(t, 100, *config.Connections[0].ConnectionPool.MaxOpenConnections)
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := LoadConfig(path)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.ErrorContains(t, err, fmt.Sprintf("failed to read MySQL config file %q", path))
+}
+
+func TestLoadConfig_MalformedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"connections": [`), 0o600))
+
+	_, err := LoadConfig(path)
+	require.EqualError(t, err, fmt.Sprintf("failed to parse MySQL config file %q: unexpected end of JSON input", path))
+}
+
+func TestOpen_WithLogger(t *testing.T) {
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "master.local:3306")
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_WPROD", "secret_w")
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_RPROD", "secret_r")
+
+	maxOpen := 100
+	config := Config{
+		Cluster: "desaenv08",
+		Schema:  "bar",
+		Connections: []Connection{
+			{
+				Name:     "master_rw",
+				IsMaster: true,
+				ConnectionPool: ConnectionPool{
+					MaxOpenConnections: &maxOpen,
+				},
+			},
+			{
+				Name:       "replica_ro",
+				IsReadOnly: true,
+			},
+			{
+				Name:       "master_ro",
+				IsMaster:   true,
+				IsReadOnly: true,
+			},
+		},
+	}
+
+	output := new(bytes.Buffer)
+	_, err := Open(config, WithLogger(logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())))
+	require.NoError(t, err)
+
+	require.Contains(t, output.String(), `MySQL connection "master_rw" opened | host: master.local:3306 | schema: bar | role: WPROD | `+
+		`pool: conn_max_lifetime=default max_idle_connections=default max_open_connections=100 conn_max_idle_time=default`)
+	require.Contains(t, output.String(), `MySQL connection "replica_ro" opened | host: <unset> | schema: bar | role: RPROD | `+
+		`pool: conn_max_lifetime=default max_idle_connections=default max_open_connections=default conn_max_idle_time=default`)
+	require.Contains(t, output.String(), `MySQL connection "master_ro" is a read-only connection to the master`)
+	require.NotContains(t, output.String(), `"master_rw" is a read-only`)
+	require.NotContains(t, output.String(), "secret")
+}
+
+func TestOpen_WithLoggerDSN(t *testing.T) {
+	config := Config{
+		DSN:         "root:secret@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "default"}},
+	}
+
+	output := new(bytes.Buffer)
+	_, err := Open(config, WithLogger(logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())))
+	require.NoError(t, err)
+
+	require.Contains(t, output.String(), `MySQL connection "default" opened | dsn: root:***@tcp(localhost:3306)/foo | pool: `)
+	require.NotContains(t, output.String(), "secret")
+}
+
+func TestOpen_DuplicateDSN(t *testing.T) {
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "master.local:3306")
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_WPROD", "secret_w")
+
+	config := Config{
+		Cluster: "desaenv08",
+		Schema:  "bar",
+		Connections: []Connection{
+			{Name: "master_rw", IsMaster: true},
+			{Name: "master_ro", IsMaster: true, IsReadOnly: true},
+			{Name: "master_rw_copy", IsMaster: true},
+		},
+	}
+
+	t.Run("warning", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		conns, err := Open(config, WithLogger(logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())))
+		require.NoError(t, err)
+		require.Len(t, conns.List(), 3)
+
+		require.Contains(t, output.String(), `MySQL connections "master_rw" and "master_rw_copy" resolve to the same DSN `+
+			`bar_WPROD:***@tcp(master.local:3306)/bar?parseTime=true`)
+		require.NotContains(t, output.String(), `"master_ro" resolve`)
+		require.NotContains(t, output.String(), "secret")
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		_, err := Open(config, WithStrictDSN())
+		require.EqualError(t, err, `invalid MySQL config: connections "master_rw" and "master_rw_copy" resolve to the same DSN `+
+			`bar_WPROD:***@tcp(master.local:3306)/bar?parseTime=true`)
+	})
+
+	t.Run("different parameters", func(t *testing.T) {
+		config := config
+		config.Connections = []Connection{
+			{Name: "master_rw", IsMaster: true},
+			{Name: "master_rw_timeout", IsMaster: true, Parameters: "timeout=1s"},
+		}
+
+		_, err := Open(config, WithStrictDSN())
+		require.NoError(t, err)
+	})
+
+	t.Run("shared DSN", func(t *testing.T) {
+		config := Config{
+			DSN:         "root:secret@tcp(localhost:3306)/foo",
+			Connections: []Connection{{Name: "first"}, {Name: "second"}},
+		}
+
+		_, err := Open(config, WithStrictDSN())
+		require.NoError(t, err)
+	})
+}
+
+func TestOpen_BestEffort(t *testing.T) {
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "master.local:3306")
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_LOCAL_REPLICA_ENDPOINT", "replica.local:3306")
+
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		if strings.Contains(name, "replica.local") {
+			return nil, errors.New("connection refused")
+		}
+		return &mocks.DriverConnMock{CloseFunc: func() error { return nil }}, nil
+	}
+
+	config := Config{
+		Cluster:    "desaenv08",
+		Schema:     "bar",
+		BestEffort: true,
+		Connections: []Connection{
+			{Name: "master", IsMaster: true},
+			{Name: "replica", IsReadOnly: true},
+			{Name: "replica_2", IsReadOnly: true},
+		},
+	}
+
+	t.Run("partial failure", func(t *testing.T) {
+		output := new(bytes.Buffer)
+		connections, err := Open(config, WithLogger(logger.NewLogger(nil, logger.WithOutput(output), logger.WithoutCallerInfo())))
+		require.EqualError(t, err, `failed to open MySQL connections: "replica": connection refused, "replica_2": connection refused`)
+
+		var partialErr *PartialOpenError
+		require.ErrorAs(t, err, &partialErr)
+		require.Len(t, partialErr.Errors, 2)
+
+		require.NotNil(t, connections)
+		require.True(t, connections.Has("master"))
+		require.False(t, connections.Has("replica"))
+		require.False(t, connections.Has("replica_2"))
+		require.Len(t, connections.List(), 1)
+
+		_, err = connections.GetReadOnly()
+		require.ErrorIs(t, err, ErrNoReadOnlyConnection)
+
+		require.NotErrorIs(t, err, ErrNoConnectionOpened)
+
+		require.Contains(t, output.String(), ` warning `)
+		require.Contains(t, output.String(), `MySQL connection "replica" failed to open, skipping it: connection refused`)
+		require.NoError(t, connections.Close())
+	})
+
+	t.Run("every connection fails", func(t *testing.T) {
+		config := config
+		config.Connections = config.Connections[1:]
+
+		connections, err := Open(config)
+		require.Nil(t, connections)
+		require.EqualError(t, err, `failed to open any MySQL connection: "replica": connection refused, "replica_2": connection refused`)
+		require.ErrorIs(t, err, ErrNoConnectionOpened)
+
+		var partialErr *PartialOpenError
+		require.False(t, errors.As(err, &partialErr))
+	})
+
+	t.Run("every connection opens", func(t *testing.T) {
+		config := config
+		config.Connections = config.Connections[:1]
+
+		connections, err := Open(config)
+		require.NoError(t, err)
+		require.True(t, connections.Has("master"))
+	})
+}
+
+func TestOpen_DriverNotRegistered(t *testing.T) {
+	registeredDrivers = func() []string { return []string{"postgres"} }
+	t.Cleanup(func() {
+		registeredDrivers = sql.Drivers
+	})
+
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
+	require.Nil(t, connections)
+	require.EqualError(t, err, "mysqlconnect_mock driver not registered; add a blank import of github.com/go-sql-driver/mysql")
+}
+
+func TestRedactDSN(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dsn      string
+		expected string
+	}{
+		{
+			name:     "with password",
+			dsn:      "root:password@tcp(localhost:3306)/foo?parseTime=true",
+			expected: "root:***@tcp(localhost:3306)/foo?parseTime=true",
+		},
+		{
+			name:     "password with special characters",
+			dsn:      "root:p@ss:w/rd@tcp(localhost:3306)/foo",
+			expected: "root:***@tcp(localhost:3306)/foo",
+		},
+		{
+			name:     "without password",
+			dsn:      "root@tcp(localhost:3306)/foo",
+			expected: "root@tcp(localhost:3306)/foo",
+		},
+		{
+			name:     "without credentials",
+			dsn:      "tcp(localhost:3306)/foo",
+			expected: "tcp(localhost:3306)/foo",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, redactDSN(tc.dsn))
+		})
+	}
+}
+
+func TestIPv6WithPort(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{name: "IPv4", host: "10.0.0.1:3306", expected: "10.0.0.1:3306"},
+		{name: "IPv4 without port", host: "10.0.0.1", expected: "10.0.0.1"},
+		{name: "host name", host: "mysql.local:3306", expected: "mysql.local:3306"},
+		{name: "bracketed IPv6", host: "[::1]:3307", expected: "[::1]:3307"},
+		{name: "bracketed IPv6 without port", host: "[2001:db8::1]", expected: "[2001:db8::1]:3306"},
+		{name: "unbracketed IPv6", host: "::1", expected: "[::1]:3306"},
+		{name: "unbracketed full IPv6", host: "2001:db8:0:0:0:0:0:1", expected: "[2001:db8:0:0:0:0:0:1]:3306"},
+		{name: "unbracketed IPv6 ending like a port", host: "2001:db8::1:3306", expected: "[2001:db8::1:3306]:3306"},
+		{name: "empty", host: "", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ipv6WithPort(tc.host))
+		})
+	}
+}
+
+func TestWithPort(t *testing.T) {
+	testCases := []struct {
+		name     string
+		host     string
+		port     int
+		expected string
+	}{
+		{name: "host only", host: "localhost", port: 3306, expected: "localhost:3306"},
+		{name: "host and port", host: "localhost:3307", port: 3306, expected: "localhost:3307"},
+		{name: "port not defined", host: "localhost", port: 0, expected: "localhost"},
+		{name: "host not defined", host: "", port: 3306, expected: ""},
+		{name: "unbracketed IPv6", host: "::1", port: 3306, expected: "[::1]:3306"},
+		{name: "bracketed IPv6 and port", host: "[::1]:3307", port: 3306, expected: "[::1]:3307"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, withPort(tc.host, tc.port))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/JhonX2011/GOWebApplication/database/mysqlconnect/nrmysqlreg
+
+go 1.23.1
+
+require github.com/newrelic/go-agent/v3/integrations/nrmysql v1.2.2
+
+require (
+	github.com/go-sql-driver/mysql v1.6.0 // indirect
+	github.com/golang/protobuf v1.4.3 // indirect
+	github.com/newrelic/go-agent/v3 v3.18.2 // indirect
+	golang.org/x/net v0.38.0 // indirect
+	golang.org/x/sys v0.31.0 // indirect
+	golang.org/x/text v0.23.0 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+	google.golang.org/grpc v1.39.0 // indirect
+	google.golang.org/protobuf v1.25.0 // indirect
+)
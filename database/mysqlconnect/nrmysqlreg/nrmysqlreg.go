@@ -0,0 +1,12 @@
+// Package nrmysqlreg registers the nrmysql driver, which mysqlconnect.Open then prefers over the mysql driver,
+// so the queries are instrumented by New Relic. It is a separate module so that the applications that don't
+// use New Relic don't depend on its agent. For example:
+//
+//	import (
+//		_ "github.com/JhonX2011/GOWebApplication/database/mysqlconnect/nrmysqlreg"
+//	)
+package nrmysqlreg
+
+import (
+	_ "github.com/newrelic/go-agent/v3/integrations/nrmysql"
+)
@@ -0,0 +1,30 @@
+package otelmysql
+
+import (
+	"database/sql/driver"
+
+	"github.com/JhonX2011/GOWebApplication/database/mysqlconnect"
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ConnectionNameKey is the attribute carrying the name of the mysqlconnect connection that issued the query.
+const ConnectionNameKey = attribute.Key("db.connection.name")
+
+// WithTracing returns a mysqlconnect.Option that wraps the driver of every connection with otelsql,
+// so every query produces an OpenTelemetry span including the statement and the connection name.
+// The spans are created with the global TracerProvider unless opts sets otherwise.
+// For example:
+//
+//	connections, err := mysqlconnect.Open(config, otelmysql.WithTracing())
+//
+// It lives in its own package so that the applications not using OpenTelemetry don't depend on it.
+func WithTracing(opts ...otelsql.Option) mysqlconnect.Option {
+	return mysqlconnect.WithDriverWrapper(func(name string, d driver.Driver) driver.Driver {
+		options := []otelsql.Option{
+			otelsql.WithAttributes(semconv.DBSystemMySQL, ConnectionNameKey.String(name)),
+		}
+		return otelsql.WrapDriver(d, append(options, opts...)...)
+	})
+}
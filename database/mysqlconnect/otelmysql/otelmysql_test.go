@@ -0,0 +1,66 @@
+package otelmysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/JhonX2011/GOWebApplication/database/mysqlconnect"
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/XSAM/otelsql"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+var mockDriver = mocks.MysqlDriverMock{}
+
+func TestWithTracing(t *testing.T) {
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						return driver.RowsAffected(1), nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	connections, err := mysqlconnect.Open(mysqlconnect.Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []mysqlconnect.Connection{{Name: "master"}},
+	}, mysqlconnect.WithDriverWrapper(func(string, driver.Driver) driver.Driver { return &mockDriver }),
+		WithTracing(otelsql.WithTracerProvider(provider)))
+	require.NoError(t, err)
+	defer connections.Close()
+
+	db, err := connections.Get("master")
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(context.Background(), "UPDATE users SET name = ? WHERE id = ?", "John", 42)
+	require.NoError(t, err)
+
+	var found bool
+	for _, span := range recorder.Ended() {
+		attributes := attribute.NewSet(span.Attributes()...)
+		statement, ok := attributes.Value(attribute.Key("db.statement"))
+		if !ok || statement.AsString() != "UPDATE users SET name = ? WHERE id = ?" {
+			continue
+		}
+
+		found = true
+		name, _ := attributes.Value(ConnectionNameKey)
+		require.Equal(t, "master", name.AsString())
+		system, _ := attributes.Value(semconv.DBSystemKey)
+		require.Equal(t, "mysql", system.AsString())
+	}
+	require.True(t, found, "a span with the statement must be recorded")
+}
@@ -0,0 +1,56 @@
+package mysqlconnect
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// ErrNoReadOnlyConnection is returned by GetReadOnly when no read-only connection is defined.
+var ErrNoReadOnlyConnection = errors.New("no read-only connection defined")
+
+// weightedReplica is a read-only connection along with its weight and its current weight
+// in the smooth weighted round-robin.
+type weightedReplica struct {
+	db      *sql.DB
+	weight  int
+	current int
+}
+
+// replicaBalancer distributes the selections among the read-only connections proportionally to their
+// weights using the smooth weighted round-robin of nginx, which spreads the selections of every
+// connection evenly instead of in bursts.
+type replicaBalancer struct {
+	mu       sync.Mutex
+	replicas []*weightedReplica
+}
+
+func (b *replicaBalancer) add(db *sql.DB, weight int) {
+	if weight <= 0 {
+		weight = 1
+	}
+	b.replicas = append(b.replicas, &weightedReplica{db: db, weight: weight})
+}
+
+// next returns the next selected connection, or nil if there is none.
+func (b *replicaBalancer) next() *sql.DB {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var total int
+	var selected *weightedReplica
+	for _, r := range b.replicas {
+		r.current += r.weight
+		total += r.weight
+		if selected == nil || r.current > selected.current {
+			selected = r
+		}
+	}
+
+	if selected == nil {
+		return nil
+	}
+
+	selected.current -= total
+	return selected.db
+}
@@ -0,0 +1,103 @@
+package mysqlconnect
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnections_GetReadOnly(t *testing.T) {
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_ENDPOINT", "master.local:3306")
+	t.Setenv("DB_MYSQL_DESAENV08_BAR_BAR_LOCAL_REPLICA_ENDPOINT", "replica.local:3306")
+
+	connections, err := Open(Config{
+		Cluster: "desaenv08",
+		Schema:  "bar",
+		Connections: []Connection{
+			{Name: "master", IsMaster: true},
+			{Name: "beefy", IsReadOnly: true, Weight: 7},
+			{Name: "small", IsReadOnly: true, Weight: 3},
+		},
+	})
+	require.NoError(t, err)
+
+	master, err := connections.Get("master")
+	require.NoError(t, err)
+	beefy, err := connections.Get("beefy")
+	require.NoError(t, err)
+	small, err := connections.Get("small")
+	require.NoError(t, err)
+
+	counts := make(map[*sql.DB]int)
+	for i := 0; i < 10000; i++ {
+		db, err := connections.GetReadOnly()
+		require.NoError(t, err)
+		counts[db]++
+	}
+
+	require.Zero(t, counts[master], "the master is not read-only")
+	require.InDelta(t, 7000, counts[beefy], 100)
+	require.InDelta(t, 3000, counts[small], 100)
+
+	connections.Pause()
+	_, err = connections.GetReadOnly()
+	require.ErrorIs(t, err, ErrPaused)
+}
+
+func TestConnections_GetReadOnlyDefaultWeights(t *testing.T) {
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{Name: "foo", IsReadOnly: true},
+			{Name: "bar", IsReadOnly: true},
+		},
+	})
+	require.NoError(t, err)
+
+	counts := make(map[*sql.DB]int)
+	for i := 0; i < 1000; i++ {
+		db, err := connections.GetReadOnly()
+		require.NoError(t, err)
+		counts[db]++
+	}
+
+	require.Len(t, counts, 2)
+	for _, count := range counts {
+		require.InDelta(t, 500, count, 10)
+	}
+}
+
+func TestConnections_GetReadOnlyNoConnection(t *testing.T) {
+	connections, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo"}},
+	})
+	require.NoError(t, err)
+
+	_, err = connections.GetReadOnly()
+	require.ErrorIs(t, err, ErrNoReadOnlyConnection)
+}
+
+func TestOpen_NegativeWeight(t *testing.T) {
+	_, err := Open(Config{
+		DSN:         "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{{Name: "foo", IsReadOnly: true, Weight: -1}},
+	})
+	require.EqualError(t, err, `invalid MySQL config: weight must not be negative: connection "foo"`)
+}
+
+func TestReplicaBalancer_SmoothDistribution(t *testing.T) {
+	a, b := &sql.DB{}, &sql.DB{}
+	balancer := &replicaBalancer{}
+	balancer.add(a, 2)
+	balancer.add(b, 1)
+
+	var selected []*sql.DB
+	for i := 0; i < 6; i++ {
+		selected = append(selected, balancer.next())
+	}
+
+	// The selections of the heavier connection are interleaved instead of sent in a burst.
+	require.Equal(t, []*sql.DB{a, b, a, a, b, a}, selected)
+}
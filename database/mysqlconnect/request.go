@@ -0,0 +1,68 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+)
+
+// RequestDB executes the queries on a connection with the context of an HTTP request, so that they are
+// canceled when the client disconnects or the request deadline is exceeded, instead of running to completion
+// for a response nobody reads.
+type RequestDB struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+// ConnFromRequest returns the connection of c with the given name bound to the context of r. It is meant to
+// be called from the handlers instead of Get, so the queries can't be run with context.Background() by mistake.
+// For example:
+//
+//	func (h *handler) getUser(w http.ResponseWriter, r *http.Request) error {
+//		db, err := mysqlconnect.ConnFromRequest(r, h.connections, "replica")
+//		if err != nil {
+//			return err
+//		}
+//
+//		var name string
+//		if err := db.QueryRow("SELECT name FROM users WHERE id = ?", web.Param(r, "id")).Scan(&name); err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// It returns the same errors as Get.
+func ConnFromRequest(r *http.Request, c Connections, name string) (*RequestDB, error) {
+	db, err := c.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RequestDB{ctx: r.Context(), db: db}, nil
+}
+
+// Exec executes a query without returning any rows with the context of the request.
+func (d *RequestDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.db.ExecContext(d.ctx, query, args...)
+}
+
+// Query executes a query that returns rows with the context of the request.
+func (d *RequestDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.QueryContext(d.ctx, query, args...)
+}
+
+// QueryRow executes a query that returns at most one row with the context of the request.
+func (d *RequestDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.db.QueryRowContext(d.ctx, query, args...)
+}
+
+// Begin starts a transaction with the context of the request, which is rolled back if the context is done
+// before it is committed.
+func (d *RequestDB) Begin(opts *sql.TxOptions) (*sql.Tx, error) {
+	return d.db.BeginTx(d.ctx, opts)
+}
+
+// DB returns the underlying connection, for the operations not bound to the request.
+func (d *RequestDB) DB() *sql.DB {
+	return d.db
+}
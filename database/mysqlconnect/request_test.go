@@ -0,0 +1,68 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnFromRequest(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT name FROM users WHERE id = ?").
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("John"))
+	mock.ExpectExec("UPDATE users SET name = ?").
+		WithArgs("Jane").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	connections := NewStatic(map[string]*sql.DB{"master": db})
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+
+	requestDB, err := ConnFromRequest(r, connections, "master")
+	require.NoError(t, err)
+	require.Same(t, db, requestDB.DB())
+
+	var name string
+	require.NoError(t, requestDB.QueryRow("SELECT name FROM users WHERE id = ?", 42).Scan(&name))
+	require.Equal(t, "John", name)
+
+	_, err = requestDB.Exec("UPDATE users SET name = ?", "Jane")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	_, err = ConnFromRequest(r, connections, "replica")
+	require.EqualError(t, err, "unknown connection name replica")
+}
+
+func TestConnFromRequest_CanceledRequest(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT SLEEP(10)").
+		WillDelayFor(10 * time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"slept"}).AddRow(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest(http.MethodGet, "/users", nil).WithContext(ctx)
+
+	requestDB, err := ConnFromRequest(r, NewStatic(map[string]*sql.DB{"master": db}), "master")
+	require.NoError(t, err)
+
+	// The client disconnects while the query is in flight.
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = requestDB.Query("SELECT SLEEP(10)")
+	require.ErrorIs(t, err, sqlmock.ErrCancelled)
+	require.Less(t, time.Since(start), time.Second)
+}
@@ -0,0 +1,174 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	_defaultRetryMaxAttempts = 3
+	_defaultRetryBackoff     = 50 * time.Millisecond
+	_defaultRetryMaxBackoff  = time.Second
+)
+
+// RetryConfig is the configuration of the RetryDB of a connection.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of times a query is executed, including the first attempt.
+	// It defaults to 3.
+	MaxAttempts int `json:"max_attempts"`
+	// Backoff is the amount of time waited before the first retry, doubled on every following retry.
+	// It defaults to 50ms.
+	Backoff Duration `json:"backoff"`
+	// MaxBackoff is the maximum amount of time waited between two attempts. It defaults to 1s.
+	MaxBackoff Duration `json:"max_backoff"`
+	// RetryWrites allows retrying the writes and the locking reads, including the queries executed with
+	// ExecContext. A write that failed with a lost connection may have been applied anyway, so it must only
+	// be enabled when the writes are idempotent. It defaults to false.
+	RetryWrites bool `json:"retry_writes"`
+}
+
+// _retryableErrorNumbers are the numbers of the MySQL errors that are transient, so the query can safely
+// be executed again.
+var _retryableErrorNumbers = map[uint16]struct{}{ //nolint:gochecknoglobals
+	1205: {}, // ER_LOCK_WAIT_TIMEOUT
+	1213: {}, // ER_LOCK_DEADLOCK
+	2006: {}, // CR_SERVER_GONE_ERROR
+	2013: {}, // CR_SERVER_LOST
+}
+
+// RetryDB executes queries on a connection pool retrying them, with an exponential backoff, when they fail
+// with driver.ErrBadConn or a transient MySQL error: lock wait timeout (1205), deadlock (1213) and
+// connection lost (2006, 2013). This smooths over brief failovers of the database.
+//
+// Only non locking read queries, the ones starting with SELECT, SHOW, DESCRIBE or EXPLAIN, are retried unless
+// RetryWrites is set. Locking reads, such as SELECT ... FOR UPDATE, take locks as the
+// writes do, so they are not retried either, as SplitDB sends them to the master. The errors returned while iterating the rows of a query are not retried.
+type RetryDB struct {
+	db          *sql.DB
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	retryWrites bool
+	sleep       func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryDB(db *sql.DB, config *RetryConfig) *RetryDB {
+	r := &RetryDB{
+		db:          db,
+		maxAttempts: _defaultRetryMaxAttempts,
+		backoff:     _defaultRetryBackoff,
+		maxBackoff:  _defaultRetryMaxBackoff,
+		sleep:       sleepContext,
+	}
+
+	if config != nil {
+		if config.MaxAttempts > 0 {
+			r.maxAttempts = config.MaxAttempts
+		}
+		if config.Backoff > 0 {
+			r.backoff = time.Duration(config.Backoff)
+		}
+		if config.MaxBackoff > 0 {
+			r.maxBackoff = time.Duration(config.MaxBackoff)
+		}
+		r.retryWrites = config.RetryWrites
+	}
+
+	return r
+}
+
+// GetWithRetry implements the Connections interface.
+func (c *connections) GetWithRetry(name string) (*RetryDB, error) {
+	if c.paused.Load() {
+		return nil, ErrPaused
+	}
+
+	retry, ok := c.retries[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown connection name %s", name)
+	}
+
+	return retry, nil
+}
+
+// ExecContext executes a query without returning any rows. It is only retried if RetryWrites is set.
+func (r *RetryDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := r.do(ctx, r.retryWrites, func() error {
+		var err error
+		result, err = r.db.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// QueryContext executes a query that returns rows.
+func (r *RetryDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := r.do(ctx, r.retryWrites || classifyQuery(query) == queryRead, func() error {
+		var err error
+		rows, err = r.db.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (r *RetryDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var row *sql.Row
+	_ = r.do(ctx, r.retryWrites || classifyQuery(query) == queryRead, func() error {
+		row = r.db.QueryRowContext(ctx, query, args...)
+		return row.Err()
+	})
+	return row
+}
+
+// do calls fn until it succeeds, fails with an error that is not retryable, the attempts are exhausted
+// or the context is done.
+func (r *RetryDB) do(ctx context.Context, retry bool, fn func() error) error {
+	backoff := r.backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !retry || attempt >= r.maxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		if sleepErr := r.sleep(ctx, backoff); sleepErr != nil {
+			return err
+		}
+		backoff = min(2*backoff, r.maxBackoff)
+	}
+}
+
+// sleepContext waits for the given duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// isRetryable reports whether err is driver.ErrBadConn or a transient MySQL error.
+func isRetryable(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr == nil {
+		return false
+	}
+	_, ok := _retryableErrorNumbers[mysqlErr.Number]
+	return ok
+}
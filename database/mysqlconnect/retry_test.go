@@ -0,0 +1,207 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	errLockWaitTimeout = &mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}
+	errDeadlock        = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	errServerLost      = &mysql.MySQLError{Number: 2013, Message: "Lost connection to MySQL server during query"}
+	errDuplicateEntry  = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+)
+
+func givenRetryDB(t *testing.T, config *RetryConfig) (*RetryDB, sqlmock.Sqlmock, *[]time.Duration) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	var sleeps []time.Duration
+	r := newRetryDB(db, config)
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return ctx.Err()
+	}
+
+	return r, mock, &sleeps
+}
+
+func TestRetryDB_QueryContextRetriesTransientErrors(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, &RetryConfig{MaxAttempts: 4, Backoff: Duration(10 * time.Millisecond)})
+
+	mock.ExpectQuery("SELECT name FROM users").WillReturnError(errDeadlock)
+	mock.ExpectQuery("SELECT name FROM users").WillReturnError(fmt.Errorf("query users: %w", errLockWaitTimeout))
+	mock.ExpectQuery("SELECT name FROM users").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	rows, err := r.QueryContext(context.Background(), "SELECT name FROM users")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	require.True(t, rows.Next())
+	var name string
+	require.NoError(t, rows.Scan(&name))
+	require.Equal(t, "foo", name)
+	require.Equal(t, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_QueryRowContextRetriesTransientErrors(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, nil)
+
+	mock.ExpectQuery("SELECT name FROM users WHERE id = ?").WithArgs(1).WillReturnError(errServerLost)
+	mock.ExpectQuery("SELECT name FROM users WHERE id = ?").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+
+	var name string
+	require.NoError(t, r.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = ?", 1).Scan(&name))
+	require.Equal(t, "foo", name)
+	require.Equal(t, []time.Duration{_defaultRetryBackoff}, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_AttemptsExhausted(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, &RetryConfig{
+		MaxAttempts: 4,
+		Backoff:     Duration(300 * time.Millisecond),
+		MaxBackoff:  Duration(500 * time.Millisecond),
+	})
+
+	for i := 0; i < 4; i++ {
+		mock.ExpectQuery("SELECT 1").WillReturnError(errDeadlock)
+	}
+
+	_, err := r.QueryContext(context.Background(), "SELECT 1")
+	require.ErrorIs(t, err, errDeadlock)
+	require.Equal(t, []time.Duration{300 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_NotRetryableError(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, nil)
+
+	mock.ExpectQuery("SELECT 1").WillReturnError(errDuplicateEntry)
+
+	_, err := r.QueryContext(context.Background(), "SELECT 1")
+	require.ErrorIs(t, err, errDuplicateEntry)
+	require.Empty(t, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_WritesAreNotRetried(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, nil)
+
+	mock.ExpectExec("UPDATE users SET name = ?").WithArgs("foo").WillReturnError(errDeadlock)
+	mock.ExpectQuery("INSERT INTO users (name) VALUES (?) RETURNING id").WithArgs("foo").WillReturnError(errDeadlock)
+
+	_, err := r.ExecContext(context.Background(), "UPDATE users SET name = ?", "foo")
+	require.ErrorIs(t, err, errDeadlock)
+
+	_, err = r.QueryContext(context.Background(), "INSERT INTO users (name) VALUES (?) RETURNING id", "foo")
+	require.ErrorIs(t, err, errDeadlock)
+
+	require.Empty(t, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_LockingReadsAreNotRetried(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, nil)
+
+	mock.ExpectQuery("SELECT id FROM users WHERE id = ? FOR UPDATE").WithArgs(1).WillReturnError(errDeadlock)
+
+	_, err := r.QueryContext(context.Background(), "SELECT id FROM users WHERE id = ? FOR UPDATE", 1)
+	require.ErrorIs(t, err, errDeadlock)
+	require.Empty(t, *sleeps)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_RetryWrites(t *testing.T) {
+	r, mock, sleeps := givenRetryDB(t, &RetryConfig{RetryWrites: true})
+
+	mock.ExpectExec("UPDATE users SET name = ?").WithArgs("foo").WillReturnError(errDeadlock)
+	mock.ExpectExec("UPDATE users SET name = ?").WithArgs("foo").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	result, err := r.ExecContext(context.Background(), "UPDATE users SET name = ?", "foo")
+	require.NoError(t, err)
+	affected, err := result.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), affected)
+	require.Len(t, *sleeps, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryDB_ContextDoneWhileWaiting(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT 1").WillReturnError(errDeadlock)
+
+	r := newRetryDB(db, &RetryConfig{Backoff: Duration(time.Minute)})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = r.QueryContext(ctx, "SELECT 1")
+	require.ErrorIs(t, err, errDeadlock)
+	require.Less(t, time.Since(start), time.Second)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "bad connection", err: driver.ErrBadConn, expected: true},
+		{name: "wrapped bad connection", err: fmt.Errorf("query: %w", driver.ErrBadConn), expected: true},
+		{name: "lock wait timeout", err: errLockWaitTimeout, expected: true},
+		{name: "deadlock", err: errDeadlock, expected: true},
+		{name: "server gone", err: &mysql.MySQLError{Number: 2006}, expected: true},
+		{name: "server lost", err: errServerLost, expected: true},
+		{name: "duplicate entry", err: errDuplicateEntry},
+		{name: "joined deadlock", err: errors.Join(errors.New("rollback failed"), errDeadlock), expected: true},
+		{name: "nil MySQL error", err: (*mysql.MySQLError)(nil)},
+		{name: "no rows", err: sql.ErrNoRows},
+		{name: "canceled", err: context.Canceled},
+		{name: "other error", err: errors.New("Error 1213: deadlock")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, isRetryable(tc.err))
+		})
+	}
+}
+
+func TestConnections_GetWithRetry(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+
+	connections := NewStatic(map[string]*sql.DB{"foo": db})
+	defer connections.Close()
+
+	retry, err := connections.GetWithRetry("foo")
+	require.NoError(t, err)
+	require.Same(t, db, retry.db)
+	require.Equal(t, _defaultRetryMaxAttempts, retry.maxAttempts)
+
+	_, err = connections.GetWithRetry("bar")
+	require.EqualError(t, err, "unknown connection name bar")
+
+	connections.Pause()
+	_, err = connections.GetWithRetry("foo")
+	require.ErrorIs(t, err, ErrPaused)
+}
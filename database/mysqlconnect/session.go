@@ -0,0 +1,119 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Session provides read-your-writes consistency to a single caller, usually a request, on top of a master
+// and its replicas: after a write through the session, its reads go to the master for a bounded window,
+// long enough to cover the replication lag, and then fall back to the replicas.
+//
+// The guarantee only covers the writes made through the session, with ExecContext or BeginTx, or reported
+// with MarkWrite, and it only holds if the replication lag is shorter than the window. Writes made by other
+// sessions or callers may still be read stale from a replica. Queries that write, such as INSERT ... RETURNING
+// executed with QueryContext, must be followed by MarkWrite.
+// A Session is safe for concurrent use.
+type Session struct {
+	master  *sql.DB
+	replica func() (*sql.DB, error)
+	paused  func() bool
+	window  time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	lastWrite time.Time
+}
+
+// Session implements the Connections interface.
+func (c *connections) Session(master string, window time.Duration) (*Session, error) {
+	db, err := c.Get(master)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		master:  db,
+		replica: c.GetReadOnly,
+		paused:  c.paused.Load,
+		window:  window,
+		now:     time.Now,
+	}, nil
+}
+
+// ExecContext executes a query without returning any rows on the master, starting the read-your-writes window.
+// It returns ErrPaused when the connections are paused.
+func (s *Session) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if s.paused() {
+		return nil, ErrPaused
+	}
+
+	s.MarkWrite()
+	return s.master.ExecContext(ctx, query, args...)
+}
+
+// BeginTx starts a transaction on the master, starting the read-your-writes window.
+// The window starts when the transaction begins, so long transactions need a MarkWrite after they commit.
+// It returns ErrPaused when the connections are paused.
+func (s *Session) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	if s.paused() {
+		return nil, ErrPaused
+	}
+
+	s.MarkWrite()
+	return s.master.BeginTx(ctx, opts)
+}
+
+// QueryContext executes a query that returns rows on the master within the read-your-writes window,
+// or on a replica otherwise. It returns ErrPaused when the connections are paused.
+func (s *Session) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that returns at most one row on the master within the read-your-writes
+// window, or on a replica otherwise. It returns ErrPaused when the connections are paused, the errors of the
+// query are deferred until the row is scanned, as with sql.DB.
+func (s *Session) QueryRowContext(ctx context.Context, query string, args ...interface{}) (*sql.Row, error) {
+	db, err := s.reader()
+	if err != nil {
+		return nil, err
+	}
+	return db.QueryRowContext(ctx, query, args...), nil
+}
+
+// MarkWrite starts the read-your-writes window, for writes made outside ExecContext and BeginTx.
+func (s *Session) MarkWrite() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastWrite = s.now()
+}
+
+// reader returns the connection the reads must be executed on. When there are no read-only connections,
+// the reads go to the master. It returns ErrPaused when the connections are paused.
+func (s *Session) reader() (*sql.DB, error) {
+	if s.paused() {
+		return nil, ErrPaused
+	}
+
+	s.mu.Lock()
+	lastWrite := s.lastWrite
+	s.mu.Unlock()
+
+	if !lastWrite.IsZero() && s.now().Sub(lastWrite) < s.window {
+		return s.master, nil
+	}
+
+	replica, err := s.replica()
+	if errors.Is(err, ErrNoReadOnlyConnection) {
+		return s.master, nil
+	}
+	return replica, err
+}
@@ -0,0 +1,128 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ReadYourWrites(t *testing.T) {
+	connections, last := givenMasterReplica(t)
+
+	session, err := connections.Session("master", time.Second)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)
+	session.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	rows, err := session.QueryContext(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "replica", last(), "reads before any write go to the replica")
+
+	_, err = session.ExecContext(ctx, "UPDATE users SET name = ?", "foo")
+	require.NoError(t, err)
+	require.Equal(t, "master", last())
+
+	now = now.Add(500 * time.Millisecond)
+	rows, err = session.QueryContext(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "master", last(), "reads within the window go to the master")
+
+	now = now.Add(time.Second)
+	rows, err = session.QueryContext(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "replica", last(), "reads after the window go to the replica")
+
+	tx, err := session.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+	require.Equal(t, "master", last())
+
+	row, err := session.QueryRowContext(ctx, "SELECT id FROM users WHERE id = ?", 1)
+	require.NoError(t, err)
+	_ = row.Err()
+	require.Equal(t, "master", last(), "a transaction starts the window")
+}
+
+func TestSession_MarkWrite(t *testing.T) {
+	connections, last := givenMasterReplica(t)
+
+	session, err := connections.Session("master", time.Second)
+	require.NoError(t, err)
+
+	session.MarkWrite()
+
+	rows, err := session.QueryContext(context.Background(), "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "master", last())
+}
+
+func TestSession_NoReadOnlyConnection(t *testing.T) {
+	connections, last := givenMasterReplica(t)
+
+	session, err := connections.Session("master", time.Second)
+	require.NoError(t, err)
+	session.replica = func() (*sql.DB, error) { return nil, ErrNoReadOnlyConnection }
+
+	rows, err := session.QueryContext(context.Background(), "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "master", last())
+}
+
+func TestSession_Paused(t *testing.T) {
+	connections, _ := givenMasterReplica(t)
+
+	session, err := connections.Session("master", time.Second)
+	require.NoError(t, err)
+
+	connections.Pause()
+
+	_, err = session.QueryContext(context.Background(), "SELECT id FROM users")
+	require.ErrorIs(t, err, ErrPaused)
+
+	_, err = session.QueryRowContext(context.Background(), "SELECT id FROM users WHERE id = ?", 1)
+	require.ErrorIs(t, err, ErrPaused)
+}
+
+func TestSession_WritesWhilePaused(t *testing.T) {
+	connections, last := givenMasterReplica(t)
+
+	session, err := connections.Session("master", time.Second)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	session.MarkWrite()
+	connections.Pause()
+
+	_, err = session.ExecContext(ctx, "UPDATE users SET name = ?", "foo")
+	require.ErrorIs(t, err, ErrPaused)
+
+	_, err = session.BeginTx(ctx, nil)
+	require.ErrorIs(t, err, ErrPaused)
+
+	_, err = session.QueryContext(ctx, "SELECT id FROM users")
+	require.ErrorIs(t, err, ErrPaused, "the reads within the window must not go to the master either")
+	require.Empty(t, last(), "no query must be issued while paused")
+
+	connections.Resume()
+	_, err = session.ExecContext(ctx, "UPDATE users SET name = ?", "foo")
+	require.NoError(t, err)
+	require.Equal(t, "master", last())
+}
+
+func TestSession_UnknownConnection(t *testing.T) {
+	connections, _ := givenMasterReplica(t)
+
+	_, err := connections.Session("foo", time.Second)
+	require.Error(t, err)
+}
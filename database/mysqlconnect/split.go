@@ -0,0 +1,128 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+)
+
+type routeKey struct{}
+
+// ForceMaster returns a copy of ctx that makes SplitDB send the queries executed with it to the master,
+// for example to read a row right after writing it, without the replication lag of the replica.
+func ForceMaster(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, true)
+}
+
+// ForceReplica returns a copy of ctx that makes SplitDB send the queries executed with QueryContext or
+// QueryRowContext to the replica, even when they don't look like read queries, for example a WITH query.
+// ExecContext and transactions still go to the master.
+func ForceReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, routeKey{}, false)
+}
+
+// SplitDB is a single handle that sends the read queries to a replica and everything else to the master.
+// The master and replica are usually resolved with Connections.Get, or Connections.GetReadOnly for the replica.
+//
+// QueryContext and QueryRowContext send a query to the replica when its leading keyword is SELECT, SHOW,
+// DESCRIBE, DESC or EXPLAIN. ExecContext and transactions always go to the master. Since only the query
+// text is inspected, some queries are routed to the master conservatively:
+//   - locking reads, such as SELECT ... FOR UPDATE, FOR SHARE or LOCK IN SHARE MODE, which need the master;
+//   - queries starting with WITH or a comment, even if they only read.
+//
+// Use ForceMaster and ForceReplica to override the routing of a single call.
+type SplitDB struct {
+	master  *sql.DB
+	replica *sql.DB
+}
+
+// NewSplitDB returns a SplitDB that sends the read queries to replica and everything else to master.
+func NewSplitDB(master, replica *sql.DB) *SplitDB {
+	return &SplitDB{master: master, replica: replica}
+}
+
+// Master returns the connection to the master.
+func (s *SplitDB) Master() *sql.DB {
+	return s.master
+}
+
+// Replica returns the connection to the replica.
+func (s *SplitDB) Replica() *sql.DB {
+	return s.replica
+}
+
+// ExecContext executes a query without returning any rows on the master.
+func (s *SplitDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.master.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows on the replica if it is a read query, or on the master otherwise.
+func (s *SplitDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return s.route(ctx, query).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that returns at most one row on the replica if it is a read query,
+// or on the master otherwise.
+func (s *SplitDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.route(ctx, query).QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx starts a transaction on the master.
+func (s *SplitDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return s.master.BeginTx(ctx, opts)
+}
+
+// route returns the connection the query must be executed on.
+func (s *SplitDB) route(ctx context.Context, query string) *sql.DB {
+	if master, ok := ctx.Value(routeKey{}).(bool); ok {
+		if master {
+			return s.master
+		}
+		return s.replica
+	}
+
+	if classifyQuery(query) == queryRead {
+		return s.replica
+	}
+	return s.master
+}
+
+// queryKind is the kind of a query, judging by its leading keyword.
+type queryKind int
+
+const (
+	// queryWrite is a query that may write, or whose kind is unknown.
+	queryWrite queryKind = iota
+	// queryLockingRead is a SELECT ... FOR UPDATE, FOR SHARE or LOCK IN SHARE MODE, which must run on the master.
+	queryLockingRead
+	// queryRead is a non locking read query: SELECT, SHOW, DESCRIBE, DESC or EXPLAIN.
+	queryRead
+)
+
+// classifyQuery returns the kind of query, judging by its leading keyword, ignoring the leading whitespace
+// and parentheses.
+func classifyQuery(query string) queryKind {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '('
+	})
+	keyword := query
+	if end >= 0 {
+		keyword = query[:end]
+	}
+
+	switch strings.ToUpper(keyword) {
+	case "SELECT":
+		normalized := " " + strings.Join(strings.Fields(strings.ToUpper(query)), " ") + " "
+		if strings.Contains(normalized, " FOR UPDATE") || strings.Contains(normalized, " FOR SHARE") ||
+			strings.Contains(normalized, " LOCK IN SHARE MODE") {
+			return queryLockingRead
+		}
+		return queryRead
+	case "SHOW", "DESCRIBE", "DESC", "EXPLAIN":
+		return queryRead
+	default:
+		return queryWrite
+	}
+}
@@ -0,0 +1,168 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// givenSplitDB returns a SplitDB over a master and a replica connection, along with a function that
+// returns the role, master or replica, of the connection that ran the last statement.
+func givenSplitDB(t *testing.T) (*SplitDB, func() string) {
+	t.Helper()
+
+	connections, last := givenMasterReplica(t)
+
+	master, err := connections.Get("master")
+	require.NoError(t, err)
+	replica, err := connections.Get("replica")
+	require.NoError(t, err)
+
+	return NewSplitDB(master, replica), last
+}
+
+// givenMasterReplica returns the Connections to a master and a replica, along with a function that
+// returns the role, master or replica, of the connection that ran the last statement.
+func givenMasterReplica(t *testing.T) (Connections, func() string) {
+	t.Helper()
+
+	var last string
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		role := "master"
+		if strings.Contains(name, "RPROD") {
+			role = "replica"
+		}
+
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				last = role
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						return driver.RowsAffected(1), nil
+					},
+					QueryFunc: func(args []driver.Value) (driver.Rows, error) {
+						return &mocks.DriverRowsMock{}, nil
+					},
+				}, nil
+			},
+			BeginFunc: func() (driver.Tx, error) {
+				last = role
+				return &mocks.DriverTxMock{RollbackFunc: func() error { return nil }}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	connections, err := Open(Config{
+		Cluster: "desaenv08",
+		Schema:  "bar",
+		Connections: []Connection{
+			{Name: "master", IsMaster: true},
+			{Name: "replica", IsReadOnly: true},
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = connections.Close() })
+
+	return connections, func() string { return last }
+}
+
+func TestSplitDB_QueryContext(t *testing.T) {
+	split, last := givenSplitDB(t)
+
+	testCases := []struct {
+		query    string
+		expected string
+	}{
+		{query: "SELECT id FROM users WHERE id = ?", expected: "replica"},
+		{query: "  select id\n FROM users", expected: "replica"},
+		{query: "(SELECT id FROM users) UNION (SELECT id FROM admins)", expected: "replica"},
+		{query: "SHOW TABLES", expected: "replica"},
+		{query: "EXPLAIN SELECT id FROM users", expected: "replica"},
+		{query: "SELECT id FROM users WHERE id = ? FOR UPDATE", expected: "master"},
+		{query: "select id from users where id = ? for\nshare", expected: "master"},
+		{query: "SELECT id FROM users LOCK IN SHARE MODE", expected: "master"},
+		{query: "WITH u AS (SELECT id FROM users) SELECT id FROM u", expected: "master"},
+		{query: "INSERT INTO users (id) VALUES (?) RETURNING id", expected: "master"},
+		{query: "/* comment */ SELECT id FROM users", expected: "master"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			rows, err := split.QueryContext(context.Background(), tc.query)
+			require.NoError(t, err)
+			require.NoError(t, rows.Close())
+			require.Equal(t, tc.expected, last())
+		})
+	}
+}
+
+func TestSplitDB_QueryRowContext(t *testing.T) {
+	split, last := givenSplitDB(t)
+
+	_ = split.QueryRowContext(context.Background(), "SELECT id FROM users WHERE id = ?", 1).Err()
+	require.Equal(t, "replica", last())
+
+	_ = split.QueryRowContext(context.Background(), "SELECT id FROM users WHERE id = ? FOR UPDATE", 1).Err()
+	require.Equal(t, "master", last())
+}
+
+func TestSplitDB_ExecAndTransactionsGoToMaster(t *testing.T) {
+	split, last := givenSplitDB(t)
+
+	_, err := split.ExecContext(ForceReplica(context.Background()), "SELECT 1")
+	require.NoError(t, err)
+	require.Equal(t, "master", last())
+
+	tx, err := split.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "master", last())
+	require.NoError(t, tx.Rollback())
+}
+
+func TestSplitDB_Override(t *testing.T) {
+	split, last := givenSplitDB(t)
+
+	rows, err := split.QueryContext(ForceMaster(context.Background()), "SELECT id FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "master", last())
+
+	rows, err = split.QueryContext(ForceReplica(context.Background()), "WITH u AS (SELECT id FROM users) SELECT id FROM u")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	require.Equal(t, "replica", last())
+}
+
+func TestClassifyQuery(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected queryKind
+	}{
+		{query: "SELECT 1", expected: queryRead},
+		{query: "\n\tselect * FROM users", expected: queryRead},
+		{query: "(SELECT id FROM a) UNION (SELECT id FROM b)", expected: queryRead},
+		{query: "SELECT(1)", expected: queryRead},
+		{query: "SHOW TABLES", expected: queryRead},
+		{query: "DESC users", expected: queryRead},
+		{query: "EXPLAIN SELECT 1", expected: queryRead},
+		{query: "SELECT id FROM users WHERE id = ? FOR UPDATE", expected: queryLockingRead},
+		{query: "select id from users\nfor share", expected: queryLockingRead},
+		{query: "SELECT id FROM users LOCK IN SHARE MODE", expected: queryLockingRead},
+		{query: "SELECTED", expected: queryWrite},
+		{query: "INSERT INTO users (name) VALUES ('foo')", expected: queryWrite},
+		{query: "UPDATE users SET name = 'foo'", expected: queryWrite},
+		{query: "CALL refresh()", expected: queryWrite},
+		{query: "", expected: queryWrite},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			require.Equal(t, tc.expected, classifyQuery(tc.query))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package mysqlconnect
+
+import (
+	"database/sql"
+)
+
+// NewStatic returns the Connections to the given databases by connection name, for example databases
+// created with sqlmock, so that the code depending on Connections can be tested without a MySQL config.
+// The connections have the default pool, circuit breaker and retry configs and none of them is read-only,
+// so GetReadOnly returns ErrNoReadOnlyConnection. Close closes the given databases.
+func NewStatic(dbs map[string]*sql.DB) Connections {
+	c := &connections{
+		dbs:      make(map[string]*sql.DB, len(dbs)),
+		breakers: make(map[string]*CircuitBreaker, len(dbs)),
+		bounded:  make(map[string]*BoundedDB, len(dbs)),
+		retries:  make(map[string]*RetryDB, len(dbs)),
+		readOnly: &replicaBalancer{},
+		pools:    make(map[string]ConnectionPool, len(dbs)),
+		infos:    make(map[string]Connection, len(dbs)),
+	}
+
+	for name, db := range dbs {
+		c.dbs[name] = db
+		c.pools[name] = ConnectionPool{}
+		c.infos[name] = Connection{Name: name}
+		c.breakers[name] = newCircuitBreaker(name, db, nil)
+		c.bounded[name] = newBoundedDB(name, db, 0)
+		c.retries[name] = newRetryDB(db, nil)
+	}
+
+	return c
+}
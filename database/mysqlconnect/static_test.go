@@ -0,0 +1,53 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStatic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT name FROM users WHERE id = ?").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("foo"))
+	mock.ExpectClose()
+
+	connections := NewStatic(map[string]*sql.DB{"foo": db})
+
+	got, err := connections.Get("foo")
+	require.NoError(t, err)
+	require.Same(t, db, got)
+	require.Equal(t, []*sql.DB{db}, connections.List())
+
+	var name string
+	require.NoError(t, got.QueryRowContext(context.Background(), "SELECT name FROM users WHERE id = ?", 1).Scan(&name))
+	require.Equal(t, "foo", name)
+
+	_, err = connections.Get("bar")
+	require.EqualError(t, err, "unknown connection name bar")
+
+	_, err = connections.GetReadOnly()
+	require.ErrorIs(t, err, ErrNoReadOnlyConnection)
+
+	require.NoError(t, connections.Close())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewStatic_CloseError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	mock.ExpectClose().WillReturnError(errors.New("close error"))
+
+	connections := NewStatic(map[string]*sql.DB{"foo": db})
+
+	require.EqualError(t, connections.Close(), "failed to close connections: foo: close error")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,83 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"golang.org/x/exp/maps"
+)
+
+// _defaultSampleInterval is how often the statistics are sampled when the given interval is not positive.
+const _defaultSampleInterval = time.Minute
+
+// ReapStats is the number of connections closed by the pool limits of a connection between two samples.
+type ReapStats struct {
+	// MaxIdleClosed is the number of connections closed due to SetMaxIdleConns.
+	MaxIdleClosed int64
+	// MaxIdleTimeClosed is the number of connections closed due to SetConnMaxIdleTime.
+	MaxIdleTimeClosed int64
+	// MaxLifetimeClosed is the number of connections closed due to SetConnMaxLifetime.
+	MaxLifetimeClosed int64
+}
+
+// Total returns the number of connections closed by any of the pool limits.
+func (s ReapStats) Total() int64 {
+	return s.MaxIdleClosed + s.MaxIdleTimeClosed + s.MaxLifetimeClosed
+}
+
+// reapStatsDelta returns the connections closed by the pool limits between the prev and curr samples.
+func reapStatsDelta(prev, curr sql.DBStats) ReapStats {
+	return ReapStats{
+		MaxIdleClosed:     curr.MaxIdleClosed - prev.MaxIdleClosed,
+		MaxIdleTimeClosed: curr.MaxIdleTimeClosed - prev.MaxIdleTimeClosed,
+		MaxLifetimeClosed: curr.MaxLifetimeClosed - prev.MaxLifetimeClosed,
+	}
+}
+
+// Stats implements the Connections interface.
+func (c *connections) Stats() map[string]sql.DBStats {
+	stats := make(map[string]sql.DBStats, len(c.dbs))
+	for name, db := range c.dbs {
+		stats[name] = db.Stats()
+	}
+	return stats
+}
+
+// WatchReaping implements the Connections interface.
+func (c *connections) WatchReaping(ctx context.Context, interval time.Duration, threshold int64,
+	fn func(name string, closed ReapStats)) {
+	if interval <= 0 {
+		interval = _defaultSampleInterval
+	}
+	if threshold <= 0 {
+		threshold = 1
+	}
+
+	names := maps.Keys(c.dbs)
+	sort.Strings(names)
+
+	prev := c.Stats()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			curr := c.Stats()
+			for _, name := range names {
+				if closed := reapStatsDelta(prev[name], curr[name]); closed.Total() >= threshold {
+					fn(name, closed)
+				}
+			}
+			prev = curr
+		}
+	}()
+}
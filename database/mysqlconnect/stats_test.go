@@ -0,0 +1,120 @@
+package mysqlconnect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func givenReapingConnections(t *testing.T) Connections {
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			CloseFunc: func() error {
+				return nil
+			},
+		}, nil
+	}
+
+	// Without idle connections every connection returned to the pool is closed due to MaxIdleConnections.
+	maxIdle := 0
+	connections, err := Open(Config{
+		DSN: "root:password@tcp(localhost:3306)/foo",
+		Connections: []Connection{
+			{
+				Name: "reaped",
+				ConnectionPool: ConnectionPool{
+					MaxIdleConnections: &maxIdle,
+				},
+			},
+			{
+				Name: "idle",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	return connections
+}
+
+func TestConnections_Stats(t *testing.T) {
+	connections := givenReapingConnections(t)
+
+	db, err := connections.Get("reaped")
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+	require.NoError(t, db.Ping())
+
+	stats := connections.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, int64(2), stats["reaped"].MaxIdleClosed)
+	require.Equal(t, int64(0), stats["reaped"].MaxLifetimeClosed)
+	require.Equal(t, int64(0), stats["idle"].MaxIdleClosed)
+}
+
+func TestConnections_WatchReaping(t *testing.T) {
+	connections := givenReapingConnections(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type reaped struct {
+		name   string
+		closed ReapStats
+	}
+	calls := make(chan reaped, 10)
+	connections.WatchReaping(ctx, 10*time.Millisecond, 1, func(name string, closed ReapStats) {
+		calls <- reaped{name: name, closed: closed}
+	})
+
+	db, err := connections.Get("reaped")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Ping())
+	}
+
+	// The pings may be split across several samples.
+	var total int64
+	for total < 3 {
+		select {
+		case call := <-calls:
+			require.Equal(t, "reaped", call.name)
+			require.Equal(t, call.closed.MaxIdleClosed, call.closed.Total())
+			total += call.closed.Total()
+		case <-time.After(time.Second):
+			t.Fatal("the reaping callback was not called")
+		}
+	}
+	require.Equal(t, int64(3), total)
+}
+
+func TestConnections_WatchReapingDefaults(t *testing.T) {
+	connections := givenReapingConnections(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	fn := func(string, ReapStats) { calls.Add(1) }
+
+	// A non-positive interval must not make the ticker panic, and a non-positive threshold must not
+	// report the samples where no connection was closed.
+	connections.WatchReaping(ctx, 0, 1, fn)
+	connections.WatchReaping(ctx, 5*time.Millisecond, 0, fn)
+
+	require.Never(t, func() bool { return calls.Load() > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+}
+
+func TestReapStatsDelta(t *testing.T) {
+	prev := sql.DBStats{MaxIdleClosed: 1, MaxIdleTimeClosed: 2, MaxLifetimeClosed: 3}
+	curr := sql.DBStats{MaxIdleClosed: 11, MaxIdleTimeClosed: 2, MaxLifetimeClosed: 8}
+
+	closed := reapStatsDelta(prev, curr)
+	require.Equal(t, ReapStats{MaxIdleClosed: 10, MaxIdleTimeClosed: 0, MaxLifetimeClosed: 5}, closed)
+	require.Equal(t, int64(15), closed.Total())
+}
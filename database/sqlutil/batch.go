@@ -0,0 +1,70 @@
+package sqlutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BatchInsert inserts rows in chunks of at most chunkSize rows, so that a large import doesn't build
+// a single statement exceeding max_allowed_packet. query is the INSERT statement up to the VALUES keyword,
+// to which the placeholders of each chunk are appended. For example:
+//
+//	err := sqlutil.BatchInsert(ctx, db, "INSERT INTO users (id, name) VALUES", [][]interface{}{
+//		{1, "John"},
+//		{2, "Jane"},
+//		{3, "Joe"},
+//	}, 2)
+//	// INSERT INTO users (id, name) VALUES (?, ?), (?, ?)
+//	// INSERT INTO users (id, name) VALUES (?, ?)
+//
+// All the chunks are executed within a transaction, which is rolled back if any of them fails.
+// Every row must have the same number of values. It does nothing when there are no rows.
+func BatchInsert(ctx context.Context, db *sql.DB, query string, rows [][]interface{}, chunkSize int) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %d: it must be greater than 0", chunkSize)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	columns := len(rows[0])
+	if columns == 0 {
+		return errors.New("invalid rows: row 0 has no values")
+	}
+	for i, row := range rows {
+		if len(row) != columns {
+			return fmt.Errorf("invalid rows: row %d has %d values, expected %d", i, len(row), columns)
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", columns), ", ") + ")"
+	for start := 0; start < len(rows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		chunk := rows[start:end]
+		args := make([]interface{}, 0, len(chunk)*columns)
+		for _, row := range chunk {
+			args = append(args, row...)
+		}
+
+		statement := strings.TrimSpace(query) + " " + strings.TrimSuffix(strings.Repeat(placeholder+", ", len(chunk)), ", ")
+		if _, err := tx.ExecContext(ctx, statement, args...); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert rows %d to %d: %w", start, end-1, err)
+		}
+	}
+
+	return tx.Commit()
+}
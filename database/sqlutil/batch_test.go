@@ -0,0 +1,166 @@
+package sqlutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var mockDriver = mocks.MysqlDriverMock{}
+
+func init() {
+	sql.Register("sqlutil_mock", &mockDriver)
+}
+
+type execCall struct {
+	query string
+	args  []driver.Value
+}
+
+// batchScenery records the statements executed on the mock driver and how the transaction ended.
+type batchScenery struct {
+	calls      []execCall
+	committed  bool
+	rolledBack bool
+}
+
+func givenBatchScenery(t *testing.T, failOnCall int) (*batchScenery, *sql.DB) {
+	t.Helper()
+
+	s := &batchScenery{}
+	mockDriver.OpenFunc = func(name string) (driver.Conn, error) {
+		return &mocks.DriverConnMock{
+			PrepareFunc: func(query string) (driver.Stmt, error) {
+				return &mocks.DriverStmtMock{
+					ExecFunc: func(args []driver.Value) (driver.Result, error) {
+						s.calls = append(s.calls, execCall{query: query, args: args})
+						if len(s.calls) == failOnCall {
+							return nil, errors.New("duplicate entry")
+						}
+						return driver.RowsAffected(int64(len(args))), nil
+					},
+				}, nil
+			},
+			BeginFunc: func() (driver.Tx, error) {
+				return &mocks.DriverTxMock{
+					CommitFunc: func() error {
+						s.committed = true
+						return nil
+					},
+					RollbackFunc: func() error {
+						s.rolledBack = true
+						return nil
+					},
+				}, nil
+			},
+			CloseFunc: func() error { return nil },
+		}, nil
+	}
+
+	db, err := sql.Open("sqlutil_mock", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return s, db
+}
+
+func givenRows(n int) [][]interface{} {
+	rows := make([][]interface{}, n)
+	for i := range rows {
+		rows[i] = []interface{}{int64(i), "name"}
+	}
+	return rows
+}
+
+func TestBatchInsert_ExactMultiple(t *testing.T) {
+	s, db := givenBatchScenery(t, 0)
+
+	err := BatchInsert(context.Background(), db, "INSERT INTO users (id, name) VALUES", givenRows(4), 2)
+	require.NoError(t, err)
+
+	require.Equal(t, []execCall{
+		{
+			query: "INSERT INTO users (id, name) VALUES (?, ?), (?, ?)",
+			args:  []driver.Value{int64(0), "name", int64(1), "name"},
+		},
+		{
+			query: "INSERT INTO users (id, name) VALUES (?, ?), (?, ?)",
+			args:  []driver.Value{int64(2), "name", int64(3), "name"},
+		},
+	}, s.calls)
+	require.True(t, s.committed)
+	require.False(t, s.rolledBack)
+}
+
+func TestBatchInsert_Remainder(t *testing.T) {
+	s, db := givenBatchScenery(t, 0)
+
+	err := BatchInsert(context.Background(), db, "INSERT INTO users (id, name) VALUES ", givenRows(5), 2)
+	require.NoError(t, err)
+
+	require.Len(t, s.calls, 3)
+	require.Equal(t, "INSERT INTO users (id, name) VALUES (?, ?), (?, ?)", s.calls[0].query)
+	require.Equal(t, "INSERT INTO users (id, name) VALUES (?, ?), (?, ?)", s.calls[1].query)
+	require.Equal(t, execCall{
+		query: "INSERT INTO users (id, name) VALUES (?, ?)",
+		args:  []driver.Value{int64(4), "name"},
+	}, s.calls[2])
+	require.True(t, s.committed)
+}
+
+func TestBatchInsert_RollbackOnFailure(t *testing.T) {
+	s, db := givenBatchScenery(t, 2)
+
+	err := BatchInsert(context.Background(), db, "INSERT INTO users (id, name) VALUES", givenRows(5), 2)
+	require.EqualError(t, err, "failed to insert rows 2 to 3: duplicate entry")
+
+	require.Len(t, s.calls, 2, "the chunks after the failure must not be executed")
+	require.True(t, s.rolledBack)
+	require.False(t, s.committed)
+}
+
+func TestBatchInsert_InvalidInput(t *testing.T) {
+	s, db := givenBatchScenery(t, 0)
+
+	testCases := []struct {
+		name       string
+		rows       [][]interface{}
+		chunkSize  int
+		errMessage string
+	}{
+		{
+			name:       "invalid chunk size",
+			rows:       givenRows(1),
+			chunkSize:  0,
+			errMessage: "invalid chunk size 0: it must be greater than 0",
+		},
+		{
+			name:       "empty row",
+			rows:       [][]interface{}{{}},
+			chunkSize:  2,
+			errMessage: "invalid rows: row 0 has no values",
+		},
+		{
+			name:       "rows of different length",
+			rows:       [][]interface{}{{1, "John"}, {2}},
+			chunkSize:  2,
+			errMessage: "invalid rows: row 1 has 1 values, expected 2",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := BatchInsert(context.Background(), db, "INSERT INTO users (id, name) VALUES", tc.rows, tc.chunkSize)
+			require.EqualError(t, err, tc.errMessage)
+		})
+	}
+
+	require.NoError(t, BatchInsert(context.Background(), db, "INSERT INTO users (id, name) VALUES", nil, 2))
+	require.Empty(t, s.calls)
+	require.False(t, s.committed)
+}
@@ -0,0 +1,64 @@
+package sqlutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JhonX2011/GOWebApplication/database/internal/sqllex"
+)
+
+// Named rewrites the :name style named parameters of query into positional ? placeholders
+// and returns the arguments in the order expected by the rewritten query, ready to be used
+// with ExecContext or QueryContext. A name used more than once is bound once per occurrence.
+// For example:
+//
+//	query, args, err := sqlutil.Named(
+//		"INSERT INTO users (id, name, updated_by) VALUES (:id, :name, :id)",
+//		map[string]interface{}{"id": 42, "name": "John"},
+//	)
+//	// query: INSERT INTO users (id, name, updated_by) VALUES (?, ?, ?)
+//	// args:  [42 John 42]
+//
+// Colons inside quoted strings and identifiers are left untouched, as well as the ones that are not
+// followed by a name, such as MySQL assignments (@total := 0).
+// It returns an error if a named parameter has no argument.
+func Named(query string, args map[string]interface{}) (string, []interface{}, error) {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	positional := make([]interface{}, 0, len(args))
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := sqllex.QuoteEnd(query, i)
+			b.WriteString(query[i:end])
+			i = end - 1
+		case c == ':' && i+1 < len(query) && isNameChar(query[i+1]):
+			end := i + 1
+			for end < len(query) && isNameChar(query[end]) {
+				end++
+			}
+
+			name := query[i+1 : end]
+			arg, ok := args[name]
+			if !ok {
+				return "", nil, fmt.Errorf("missing argument for named parameter %q", name)
+			}
+
+			b.WriteByte('?')
+			positional = append(positional, arg)
+			i = end - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), positional, nil
+}
+
+// isNameChar reports whether c can be part of the name of a named parameter.
+func isNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,80 @@
+package sqlutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNamed(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		args         map[string]interface{}
+		expected     string
+		expectedArgs []interface{}
+	}{
+		{
+			name:         "in order",
+			query:        "INSERT INTO users (id, name) VALUES (:id, :name)",
+			args:         map[string]interface{}{"id": 42, "name": "John"},
+			expected:     "INSERT INTO users (id, name) VALUES (?, ?)",
+			expectedArgs: []interface{}{42, "John"},
+		},
+		{
+			name:         "out of order",
+			query:        "UPDATE users SET name = :name, email = :email WHERE id = :id",
+			args:         map[string]interface{}{"id": 42, "email": "john@example.com", "name": "John"},
+			expected:     "UPDATE users SET name = ?, email = ? WHERE id = ?",
+			expectedArgs: []interface{}{"John", "john@example.com", 42},
+		},
+		{
+			name:         "repeated names",
+			query:        "INSERT INTO users (id, created_by, updated_by) VALUES (:id, :user_id, :user_id)",
+			args:         map[string]interface{}{"id": 42, "user_id": 7},
+			expected:     "INSERT INTO users (id, created_by, updated_by) VALUES (?, ?, ?)",
+			expectedArgs: []interface{}{42, 7, 7},
+		},
+		{
+			name:         "unused arguments",
+			query:        "SELECT * FROM users WHERE id = :id",
+			args:         map[string]interface{}{"id": 42, "name": "John"},
+			expected:     "SELECT * FROM users WHERE id = ?",
+			expectedArgs: []interface{}{42},
+		},
+		{
+			name:         "colons in quotes",
+			query:        "SELECT ':id', \":id\", `:id`, 'it''s :id', 'it\\'s :id' FROM users WHERE id = :id",
+			args:         map[string]interface{}{"id": 42},
+			expected:     "SELECT ':id', \":id\", `:id`, 'it''s :id', 'it\\'s :id' FROM users WHERE id = ?",
+			expectedArgs: []interface{}{42},
+		},
+		{
+			name:         "assignment",
+			query:        "SELECT @total := @total + amount FROM payments WHERE user_id = :user_id",
+			args:         map[string]interface{}{"user_id": 7},
+			expected:     "SELECT @total := @total + amount FROM payments WHERE user_id = ?",
+			expectedArgs: []interface{}{7},
+		},
+		{
+			name:         "no parameters",
+			query:        "SELECT 1",
+			expected:     "SELECT 1",
+			expectedArgs: []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := Named(tt.query, tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, query)
+			require.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestNamed_MissingArgument(t *testing.T) {
+	_, _, err := Named("SELECT * FROM users WHERE id = :id AND name = :name", map[string]interface{}{"id": 42})
+	require.EqualError(t, err, `missing argument for named parameter "name"`)
+}
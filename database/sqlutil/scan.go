@@ -0,0 +1,90 @@
+package sqlutil
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ScanAll scans every row of rows into a T, which must be a struct, and closes rows. The columns are mapped
+// to the exported fields of T by their db tag, e.g. `db:"created_at"`. For example:
+//
+//	type user struct {
+//		ID    int64   `db:"id"`
+//		Name  string  `db:"name"`
+//		Email *string `db:"email"`
+//	}
+//
+//	rows, err := db.QueryContext(ctx, "SELECT id, name, email FROM users")
+//	if err != nil {
+//		return err
+//	}
+//	users, err := sqlutil.ScanAll[user](rows)
+//
+// Nullable columns must be mapped to pointer fields, or to sql.Null* fields, which are left nil on NULL.
+// It returns an error if a column has no field with its tag, so that a renamed field doesn't silently
+// leave its column unread.
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid destination type %s: it must be a struct", typ)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := tagFields(typ)
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		index, ok := fields[column]
+		if !ok {
+			return nil, fmt.Errorf("missing field for column %q in %s", column, typ)
+		}
+		indexes[i] = index
+	}
+
+	var result []T
+	dest := make([]interface{}, len(columns))
+	for rows.Next() {
+		var v T
+		value := reflect.ValueOf(&v).Elem()
+		for i, index := range indexes {
+			dest[i] = value.Field(index).Addr().Interface()
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// tagFields returns the index of the exported fields of typ by the column name of their db tag.
+// The fields without a db tag, or tagged with "-", are skipped.
+func tagFields(typ reflect.Type) map[string]int {
+	fields := make(map[string]int, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = i
+	}
+	return fields
+}
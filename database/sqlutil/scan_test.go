@@ -0,0 +1,79 @@
+package sqlutil
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+)
+
+type scanUser struct {
+	ID       int64          `db:"id"`
+	Name     string         `db:"name"`
+	Email    *string        `db:"email"`
+	Nickname sql.NullString `db:"nickname"`
+	Ignored  string
+}
+
+func givenScanRows(t *testing.T, rows *sqlmock.Rows) *sql.Rows {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		// ScanAll must close the rows.
+		require.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+
+	mock.ExpectQuery("SELECT").WillReturnRows(rows).RowsWillBeClosed()
+
+	result, err := db.Query("SELECT")
+	require.NoError(t, err)
+	return result
+}
+
+func TestScanAll(t *testing.T) {
+	rows := givenScanRows(t, sqlmock.NewRows([]string{"id", "name", "email", "nickname"}).
+		AddRow(1, "John", "john@example.com", "johnny").
+		AddRow(2, "Jane", nil, nil))
+
+	users, err := ScanAll[scanUser](rows)
+	require.NoError(t, err)
+
+	email := "john@example.com"
+	require.Equal(t, []scanUser{
+		{ID: 1, Name: "John", Email: &email, Nickname: sql.NullString{String: "johnny", Valid: true}},
+		{ID: 2, Name: "Jane"},
+	}, users)
+}
+
+func TestScanAll_NoRows(t *testing.T) {
+	rows := givenScanRows(t, sqlmock.NewRows([]string{"id", "name"}))
+
+	users, err := ScanAll[scanUser](rows)
+	require.NoError(t, err)
+	require.Empty(t, users)
+}
+
+func TestScanAll_NullIntoNonNullableField(t *testing.T) {
+	rows := givenScanRows(t, sqlmock.NewRows([]string{"id", "name"}).AddRow(1, nil))
+
+	_, err := ScanAll[scanUser](rows)
+	require.ErrorContains(t, err, `converting NULL to string is unsupported`)
+}
+
+func TestScanAll_MissingField(t *testing.T) {
+	rows := givenScanRows(t, sqlmock.NewRows([]string{"id", "age"}).AddRow(1, 42))
+
+	_, err := ScanAll[scanUser](rows)
+	require.EqualError(t, err, `missing field for column "age" in sqlutil.scanUser`)
+}
+
+func TestScanAll_NotAStruct(t *testing.T) {
+	rows := givenScanRows(t, sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := ScanAll[int64](rows)
+	require.EqualError(t, err, "invalid destination type int64: it must be a struct")
+}
@@ -1,6 +1,9 @@
 package infrastructuremock
 
 import (
+	"io"
+
+	"github.com/JhonX2011/GOWebApplication/utils/logger"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -8,6 +11,8 @@ type MockLogger struct {
 	mock.Mock
 }
 
+var _ logger.Logger = (*MockLogger)(nil)
+
 type OSExitMock struct {
 	mock.Mock
 }
@@ -63,3 +68,34 @@ func (m *MockLogger) Debug(params ...interface{}) {
 func (m *MockLogger) Debugf(msg string, params ...interface{}) {
 	m.Called(msg, params)
 }
+
+func (m *MockLogger) ErrorWithStack(err error) {
+	m.Called(err)
+}
+
+func (m *MockLogger) Log(level logger.Level, params ...interface{}) {
+	m.Called(level, params)
+}
+
+func (m *MockLogger) Logf(level logger.Level, msg string, params ...interface{}) {
+	m.Called(level, msg, params)
+}
+
+func (m *MockLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	args := m.Called(fields)
+	return args.Get(0).(logger.Logger)
+}
+
+func (m *MockLogger) Named(name string) logger.Logger {
+	args := m.Called(name)
+	return args.Get(0).(logger.Logger)
+}
+
+func (m *MockLogger) SetLevelEnabled(level logger.Level, enabled bool) {
+	m.Called(level, enabled)
+}
+
+func (m *MockLogger) Writer(level logger.Level) io.Writer {
+	args := m.Called(level)
+	return args.Get(0).(io.Writer)
+}
@@ -3,6 +3,7 @@ package infrastructuremock
 import (
 	"context"
 	"database/sql/driver"
+	"io"
 	"strings"
 )
 
@@ -59,3 +60,60 @@ func (d *DriverConnMock) Close() error {
 func (d *DriverConnMock) Begin() (driver.Tx, error) {
 	return d.BeginFunc()
 }
+
+type DriverStmtMock struct {
+	ExecFunc  func(args []driver.Value) (driver.Result, error)
+	QueryFunc func(args []driver.Value) (driver.Rows, error)
+}
+
+func (s *DriverStmtMock) Close() error {
+	return nil
+}
+
+func (s *DriverStmtMock) NumInput() int {
+	return -1
+}
+
+func (s *DriverStmtMock) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecFunc(args)
+}
+
+func (s *DriverStmtMock) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryFunc(args)
+}
+
+type DriverRowsMock struct {
+	ColumnsValue []string
+	Values       [][]driver.Value
+	next         int
+}
+
+func (r *DriverRowsMock) Columns() []string {
+	return r.ColumnsValue
+}
+
+func (r *DriverRowsMock) Close() error {
+	return nil
+}
+
+func (r *DriverRowsMock) Next(dest []driver.Value) error {
+	if r.next >= len(r.Values) {
+		return io.EOF
+	}
+	copy(dest, r.Values[r.next])
+	r.next++
+	return nil
+}
+
+type DriverTxMock struct {
+	CommitFunc   func() error
+	RollbackFunc func() error
+}
+
+func (t *DriverTxMock) Commit() error {
+	return t.CommitFunc()
+}
+
+func (t *DriverTxMock) Rollback() error {
+	return t.RollbackFunc()
+}
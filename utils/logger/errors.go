@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// stackTracer is implemented by the errors of github.com/pkg/errors that carry the stack trace
+// captured when they were created or wrapped.
+type stackTracer interface {
+	StackTrace() pkgerrors.StackTrace
+}
+
+// errorChain returns the messages of the errors wrapped by err, one per line prefixed with "caused by:",
+// each followed by its stack trace when it carries one. The errors of github.com/pkg/errors that only add
+// a stack trace repeat the message of the error they wrap, so their message is not repeated.
+// It returns nil when err wraps no error and carries no stack trace.
+func errorChain(err error) []byte {
+	var b strings.Builder
+	previous := err.Error()
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if msg := e.Error(); e != err && msg != previous {
+			b.WriteString("caused by: " + msg + "\n")
+			previous = msg
+		}
+
+		if st, ok := e.(stackTracer); ok {
+			// The stack trace is formatted as a function per line followed by its tab indented file:line.
+			b.WriteString(strings.TrimPrefix(fmt.Sprintf("%+v", st.StackTrace()), "\n") + "\n")
+		}
+	}
+
+	if b.Len() == 0 {
+		return nil
+	}
+	return []byte(b.String())
+}
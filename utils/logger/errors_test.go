@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerErrorWithStack_Wrapped(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+
+	err := fmt.Errorf("failed to save user: %w", fmt.Errorf("failed to insert: %w", errors.New("connection refused")))
+	l.ErrorWithStack(err)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "| failed to save user: failed to insert: connection refused ")
+	assert.Equal(t, "caused by: failed to insert: connection refused", lines[1])
+	assert.Equal(t, "caused by: connection refused", lines[2])
+}
+
+func TestLoggerErrorWithStack_Stack(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+
+	err := fmt.Errorf("failed to save user: %w", pkgerrors.New("connection refused"))
+	l.ErrorWithStack(err)
+
+	lines := strings.Split(output.String(), "\n")
+	assert.Contains(t, lines[0], "| failed to save user: connection refused ")
+	assert.Equal(t, "caused by: connection refused", lines[1])
+	assert.Contains(t, lines[2], "TestLoggerErrorWithStack_Stack")
+	assert.Contains(t, lines[3], "errors_test.go:")
+}
+
+func TestLoggerErrorWithStack_NoChain(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo(), WithFormat(FormatLogfmt))
+
+	l.ErrorWithStack(errors.New("connection refused"))
+	l.ErrorWithStack(nil)
+
+	assert.Regexp(t, `^ts=\S+ level=error msg="connection refused"\n$`, output.String())
+}
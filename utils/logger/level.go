@@ -0,0 +1,87 @@
+package logger
+
+import "fmt"
+
+// Level is the severity of a log line.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarning
+	LevelError
+	LevelFatal
+	LevelPanic
+)
+
+// String returns the lower case name of the level, e.g. "warning".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "error"
+	case LevelFatal:
+		return "fatal"
+	case LevelPanic:
+		return "panic"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// label returns the name of the level as written by the text format.
+func (l Level) label() string {
+	switch l {
+	case LevelDebug:
+		return "Debug"
+	case LevelInfo:
+		return "Info"
+	case LevelWarning:
+		return "warning"
+	case LevelError:
+		return "Error"
+	case LevelFatal:
+		return "Fatal"
+	case LevelPanic:
+		return "Panic"
+	default:
+		return l.String()
+	}
+}
+
+// The ANSI colors of the level column in the text format.
+const (
+	green   = "\033[97;42m"
+	white   = "\033[90;47m"
+	yellow  = "\033[90;43m"
+	red     = "\033[97;41m"
+	blue    = "\033[97;44m"
+	magenta = "\033[97;45m"
+	cyan    = "\033[97;46m"
+	reset   = "\033[0m"
+)
+
+// color returns the ANSI color of the level column in the text format.
+func (l Level) color() string {
+	switch l {
+	case LevelDebug:
+		return green
+	case LevelInfo:
+		return blue
+	case LevelWarning:
+		return yellow
+	case LevelError:
+		return red
+	case LevelFatal:
+		return magenta
+	case LevelPanic:
+		return cyan
+	default:
+		return white
+	}
+}
@@ -0,0 +1,26 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level    Level
+		expected string
+	}{
+		{LevelDebug, "debug"},
+		{LevelInfo, "info"},
+		{LevelWarning, "warning"},
+		{LevelError, "error"},
+		{LevelFatal, "fatal"},
+		{LevelPanic, "panic"},
+		{Level(42), "Level(42)"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.level.String())
+	}
+}
@@ -1,36 +1,35 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
-const (
-	green   = "\033[97;42m"
-	white   = "\033[90;47m"
-	yellow  = "\033[90;43m"
-	red     = "\033[97;41m"
-	blue    = "\033[97;44m"
-	magenta = "\033[97;45m"
-	cyan    = "\033[97;46m"
-	reset   = "\033[0m"
-)
-
-const (
-	gError  = "Error"
-	fatal   = "Fatal"
-	panico  = "Panic"
-	info    = "Info"
-	warning = "warning"
-	debug   = "Debug"
-)
-
-const value = 1
+// value is the number of stack frames to skip to reach the caller of the logger methods.
+const value = 2
 
 type logger struct {
 	osExitFunc func(int) // out function of SS.OO
+	out        io.Writer
+	omitCaller bool
+	format     Format
+	caller     func(skip int) (pc uintptr, file string, line int, ok bool)
+	clock      func() time.Time
+	precision  TimestampPrecision
+	segments   int
+	counter    LevelCounter
+	layout     *textLayout // nil for _defaultTextLayout
+	fields     map[string]interface{}
+	name       string         // component name, see Named
+	disabled   *atomic.Uint32 // bitmask of the disabled levels, shared with the child loggers
 }
 
 type Logger interface {
@@ -40,128 +39,532 @@ type Logger interface {
 	Panicf(string, ...interface{})
 	Error(...interface{})
 	Errorf(string, ...interface{})
+	ErrorWithStack(error)
 	Info(...interface{})
 	Infof(string, ...interface{})
 	Warning(...interface{})
 	Warningf(string, ...interface{})
 	Debug(...interface{})
 	Debugf(string, ...interface{})
+	Log(Level, ...interface{})
+	Logf(Level, string, ...interface{})
+	WithFields(map[string]interface{}) Logger
+	Named(string) Logger
+	SetLevelEnabled(Level, bool)
+	Writer(Level) io.Writer
+}
+
+// LevelCounter counts the log lines emitted per level, for example backed by a Prometheus counter vector,
+// so that the volume of log lines can be monitored without coupling the logger to a metrics library.
+type LevelCounter interface {
+	Inc(level Level)
+}
+
+// Format is the output format of the log lines.
+type Format int
+
+const (
+	// FormatText writes the log lines as pipe separated columns. It is the default format.
+	FormatText Format = iota
+	// FormatLogfmt writes the log lines as logfmt key=value pairs, e.g.
+	// ts=2024-05-12T10:00:00Z level=info file=main.go:12 func=main.main() msg="server started".
+	FormatLogfmt
+	// FormatJSON writes the log lines as JSON objects, e.g.
+	// {"ts":"2024-05-12T10:00:00Z","level":"info","file":"main.go:12","func":"main.main()","msg":"server started"}.
+	FormatJSON
+)
+
+// TimestampPrecision is the precision of the timestamp of the log lines.
+type TimestampPrecision int
+
+const (
+	// PrecisionSecond writes the timestamps with second precision. It is the default precision.
+	PrecisionSecond TimestampPrecision = iota
+	// PrecisionMillisecond writes the timestamps with millisecond precision, e.g. 2024/05/12 - 10:00:00.123.
+	PrecisionMillisecond
+	// PrecisionMicrosecond writes the timestamps with microsecond precision, e.g. 2024/05/12 - 10:00:00.123456.
+	PrecisionMicrosecond
+)
+
+// textLayout is the layout of the columns of the text format.
+type textLayout struct {
+	separator string
+	fileWidth int
+	funcWidth int
+}
+
+// _defaultTextLayout is the layout of the text format unless changed with WithSeparator or WithColumnWidths.
+var _defaultTextLayout = textLayout{separator: " | ", fileWidth: 20, funcWidth: 20} //nolint:gochecknoglobals
+
+// Option configures the optional behavior of a Logger created with NewLogger.
+type Option func(*logger)
+
+// WithOutput sets the writer where the log lines are written. By default, os.Stdout is used.
+func WithOutput(w io.Writer) Option {
+	return func(l *logger) {
+		l.out = w
+	}
+}
+
+// WithFormat sets the output format of the log lines. By default, FormatText is used.
+func WithFormat(f Format) Option {
+	return func(l *logger) {
+		l.format = f
+	}
+}
+
+// WithoutCallerInfo omits the file and func columns from the log lines.
+// The caller lookup is skipped entirely, which makes logging cheaper for high-volume logs.
+func WithoutCallerInfo() Option {
+	return func(l *logger) {
+		l.omitCaller = true
+	}
+}
+
+// WithClock sets the function used to get the timestamp of the log lines. By default, time.Now is used.
+// It is meant for tests, which can freeze the time to assert the exact output.
+func WithClock(clock func() time.Time) Option {
+	return func(l *logger) {
+		l.clock = clock
+	}
+}
+
+// WithTimestampPrecision sets the precision of the timestamp of the log lines, so the order of the lines
+// written within the same second is not ambiguous. By default, PrecisionSecond is used.
+func WithTimestampPrecision(p TimestampPrecision) Option {
+	return func(l *logger) {
+		l.precision = p
+	}
+}
+
+// WithSeparator sets the separator between the columns of the text format. By default, " | " is used.
+func WithSeparator(separator string) Option {
+	return func(l *logger) {
+		layout := l.textLayout()
+		layout.separator = separator
+		l.layout = &layout
+	}
+}
+
+// WithColumnWidths sets the minimum widths of the file and func columns of the text format, which are
+// right aligned and padded with spaces up to them. A width of 0 disables the padding. By default, both are 20.
+func WithColumnWidths(file, fn int) Option {
+	return func(l *logger) {
+		layout := l.textLayout()
+		layout.fileWidth, layout.funcWidth = file, fn
+		l.layout = &layout
+	}
+}
+
+// WithFileSegments keeps the last n segments of the file path in the file column, e.g. foo/handler.go:12
+// for n = 2, to tell apart files with the same name in different packages. By default, only the file name is kept.
+func WithFileSegments(n int) Option {
+	return func(l *logger) {
+		l.segments = n
+	}
+}
+
+// WithLevelCounter sets the counter that is incremented on every emitted log line with its level.
+// The lines of disabled levels, and the Debug lines when MODE_DEBUG is not true, are not counted.
+// The counter is shared with the child loggers and must be safe for concurrent use.
+func WithLevelCounter(c LevelCounter) Option {
+	return func(l *logger) {
+		l.counter = c
+	}
 }
 
-func NewLogger(fn func(int)) Logger {
-	return &logger{
+func NewLogger(fn func(int), opts ...Option) Logger {
+	l := &logger{
 		osExitFunc: fn,
+		disabled:   new(atomic.Uint32),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// SetLevelEnabled enables or disables the output of the given level, independently of the other levels.
+// All the levels are enabled by default, and Debug additionally requires MODE_DEBUG=true.
+// Disabling Fatal or Panic only suppresses the log line: the process still exits or panics.
+// The setting is shared with the child loggers.
+func (l *logger) SetLevelEnabled(level Level, enabled bool) {
+	if l.disabled == nil {
+		l.disabled = new(atomic.Uint32)
+	}
+
+	for {
+		old := l.disabled.Load()
+		updated := old | 1<<level
+		if enabled {
+			updated = old &^ (1 << level)
+		}
+		if l.disabled.CompareAndSwap(old, updated) {
+			return
+		}
 	}
 }
 
-func (l *logger) Fatal(v ...interface{}) {
+// enabled reports whether the output of the given level is enabled.
+func (l *logger) enabled(level Level) bool {
+	return l.disabled == nil || l.disabled.Load()&(1<<level) == 0
+}
+
+// WithFields returns a child logger that appends the given fields to every line, along with the fields
+// of its parent. The child shares the configuration of its parent.
+func (l *logger) WithFields(fields map[string]interface{}) Logger {
+	child := *l
+	child.fields = make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+	return &child
+}
+
+// Named returns a child logger for the component with the given name, which prefixes its messages with
+// the name between brackets in the text format, e.g. [payments] message, and adds it as the component key
+// in the logfmt and JSON formats. The names of nested calls are joined with a dot, e.g. payments.refunds.
+// The child shares the configuration and the fields of its parent.
+func (l *logger) Named(name string) Logger {
+	child := *l
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child.name = name
+	return &child
+}
+
+// print writes a log line with the given level and message, followed by the stack trace when it is not nil.
+// It must be called directly from the exported methods so that the caller lookup reports the right file and func.
+func (l *logger) print(level Level, msg string, stack []byte) {
+	var file, fn string
+	if !l.omitCaller {
+		pc, fi, li, ok := l.callerFunc()(value)
+		file, fn = FileInfoN(fi, li, ok, l.segments), FuncInfo(runtime.FuncForPC(pc).Name())
+	}
+
+	l.write(level, file, fn, msg, stack)
+}
+
+// callerFunc returns the function used to look up the callers, runtime.Caller unless another one is injected.
+func (l *logger) callerFunc() func(skip int) (pc uintptr, file string, line int, ok bool) {
+	if l.caller == nil {
+		return runtime.Caller
+	}
+	return l.caller
+}
+
+// write writes a log line with the given level, caller file and func, message and stack trace.
+func (l *logger) write(level Level, file, fn, msg string, stack []byte) {
 	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), magenta, fatal, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if l.clock != nil {
+		now = l.clock()
+	}
+
+	out := l.out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	switch l.format {
+	case FormatLogfmt:
+		fmt.Fprint(out, l.formatLogfmt(now, level, file, fn, msg, stack))
+	case FormatJSON:
+		fmt.Fprint(out, l.formatJSON(now, level, file, fn, msg, stack))
+	default:
+		fmt.Fprint(out, l.formatText(now, level, file, fn, msg, stack))
+	}
+
+	if l.counter != nil {
+		l.counter.Inc(level)
+	}
+}
+
+// formatText formats a log line as pipe separated columns. The file and func columns are omitted when empty.
+// The stack trace, if any, is written as is after the line.
+func (l *logger) formatText(now time.Time, level Level, file, fn, msg string, stack []byte) string {
+	if l.name != "" {
+		msg = "[" + l.name + "] " + msg
+	}
+	if len(l.fields) > 0 {
+		msg = msg + " " + FormatFields(l.fields)
+	}
+
+	layout := l.textLayout()
+	columns := []string{l.formatTextTime(now), colorize(level)}
+	if !l.omitCaller {
+		columns = append(columns, fmt.Sprintf("%*s", layout.fileWidth, file), fmt.Sprintf("%*s", layout.funcWidth, fn))
+	}
+	columns = append(columns, msg+" ")
+
+	line := strings.Join(columns, layout.separator) + "\n"
+
+	if len(stack) > 0 {
+		line += strings.TrimRight(string(stack), "\n") + "\n"
+	}
+
+	return line
+}
+
+// colorize returns the level column of the text format, its label on the background color of the level.
+// The colors are only used by the text format, the other formats write the plain name of the level.
+func colorize(level Level) string {
+	return level.color() + " " + level.label() + " " + reset
+}
+
+// textLayout returns the layout of the columns of the text format.
+func (l *logger) textLayout() textLayout {
+	if l.layout == nil {
+		return _defaultTextLayout
+	}
+	return *l.layout
+}
+
+// formatTextTime formats the timestamp of the text format with the configured precision.
+func (l *logger) formatTextTime(now time.Time) string {
+	switch l.precision {
+	case PrecisionMillisecond:
+		return now.Format("2006/01/02 - 15:04:05.000")
+	case PrecisionMicrosecond:
+		return now.Format("2006/01/02 - 15:04:05.000000")
+	default:
+		return FormatNow(now)
+	}
+}
+
+// formatLogfmtTime formats the timestamp of the logfmt format as RFC 3339 with the configured precision.
+func (l *logger) formatLogfmtTime(now time.Time) string {
+	switch l.precision {
+	case PrecisionMillisecond:
+		return now.Format("2006-01-02T15:04:05.000Z07:00")
+	case PrecisionMicrosecond:
+		return now.Format("2006-01-02T15:04:05.000000Z07:00")
+	default:
+		return now.Format(time.RFC3339)
+	}
+}
+
+// formatLogfmt formats a log line as logfmt key=value pairs. The file and func keys are omitted when empty.
+// The stack trace, if any, is written as the stack key.
+func (l *logger) formatLogfmt(now time.Time, level Level, file, fn, msg string, stack []byte) string {
+	var b strings.Builder
+	b.WriteString("ts=" + l.formatLogfmtTime(now))
+	b.WriteString(" level=" + level.String())
+	if !l.omitCaller {
+		b.WriteString(" file=" + FormatValue(file))
+		b.WriteString(" func=" + FormatValue(fn))
+	}
+	if l.name != "" {
+		b.WriteString(" component=" + FormatValue(l.name))
+	}
+	b.WriteString(" msg=" + FormatValue(msg))
+	if len(l.fields) > 0 {
+		b.WriteString(" " + FormatFields(l.fields))
+	}
+	if len(stack) > 0 {
+		b.WriteString(" stack=" + FormatValue(strings.TrimRight(string(stack), "\n")))
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// formatJSON formats a log line as a JSON object followed by a newline. The file and func keys are omitted
+// when empty, and the fields are added as keys of the object after msg, sorted by key.
+// The stack trace, if any, is written as the stack key.
+func (l *logger) formatJSON(now time.Time, level Level, file, fn, msg string, stack []byte) string {
+	var b strings.Builder
+	b.WriteString(`{"ts":` + jsonValue(l.formatLogfmtTime(now)))
+	b.WriteString(`,"level":` + jsonValue(level.String()))
+	if !l.omitCaller {
+		b.WriteString(`,"file":` + jsonValue(file))
+		b.WriteString(`,"func":` + jsonValue(fn))
+	}
+	if l.name != "" {
+		b.WriteString(`,"component":` + jsonValue(l.name))
+	}
+	b.WriteString(`,"msg":` + jsonValue(msg))
+
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("," + jsonValue(k) + ":" + jsonValue(l.fields[k]))
+	}
+
+	if len(stack) > 0 {
+		b.WriteString(`,"stack":` + jsonValue(strings.TrimRight(string(stack), "\n")))
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// jsonValue returns v encoded as JSON without escaping HTML, or its fmt.Sprint string when it can't be encoded,
+// so that a field never breaks the log line.
+func jsonValue(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		b.Reset()
+		_ = enc.Encode(fmt.Sprint(v))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (l *logger) Fatal(v ...interface{}) {
+	if l.enabled(LevelFatal) {
+		l.print(LevelFatal, fmt.Sprintf("%s", v), nil)
+	}
 	l.osExitFunc(1)
 }
 
 func (l *logger) Fatalf(format string, args ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), magenta, fatal, reset, FileInfo(fi, li, ok), FuncInfo(f),
-		fmt.Errorf(format, args...).Error())
+	if l.enabled(LevelFatal) {
+		l.print(LevelFatal, fmt.Errorf(format, args...).Error(), nil)
+	}
 	l.osExitFunc(1)
 }
 
+// Panic logs v along with the stack trace of the current goroutine and then panics with fmt.Sprint(v...).
 func (l *logger) Panic(v ...interface{}) {
-	now := time.Now()
 	s := fmt.Sprint(v...)
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), cyan, panico, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if l.enabled(LevelPanic) {
+		l.print(LevelPanic, fmt.Sprintf("%s", v), debug.Stack())
+	}
 	panic(s)
 }
 
+// Panicf logs the formatted message along with the stack trace of the current goroutine
+// and then panics with the formatted message.
 func (l *logger) Panicf(format string, args ...interface{}) {
-	now := time.Now()
 	s := fmt.Sprintf(format, args...)
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), cyan, panico, reset, FileInfo(fi, li, ok), FuncInfo(f),
-		fmt.Errorf(format, args...).Error())
+	if l.enabled(LevelPanic) {
+		l.print(LevelPanic, fmt.Errorf(format, args...).Error(), debug.Stack())
+	}
 	panic(s)
 }
 
 func (l *logger) Error(v ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), red, gError, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if l.enabled(LevelError) {
+		l.print(LevelError, fmt.Sprintf("%s", v), nil)
+	}
 }
 
 func (l *logger) Errorf(format string, args ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), red, gError, reset, FileInfo(fi, li, ok), FuncInfo(f),
-		fmt.Errorf(format, args...).Error())
+	if l.enabled(LevelError) {
+		l.print(LevelError, fmt.Errorf(format, args...).Error(), nil)
+	}
+}
+
+// ErrorWithStack logs err at Error level followed by its chain of wrapped errors, unwrapped with errors.Unwrap,
+// and the stack traces captured by github.com/pkg/errors along the chain. It does nothing when err is nil.
+func (l *logger) ErrorWithStack(err error) {
+	if err != nil && l.enabled(LevelError) {
+		l.print(LevelError, err.Error(), errorChain(err))
+	}
 }
 
 func (l *logger) Info(v ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), blue, info, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if l.enabled(LevelInfo) {
+		l.print(LevelInfo, fmt.Sprintf("%s", v), nil)
+	}
 }
 
 func (l *logger) Infof(format string, args ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), blue, info, reset, FileInfo(fi, li, ok), FuncInfo(f),
-		fmt.Sprintf(format, args...))
+	if l.enabled(LevelInfo) {
+		l.print(LevelInfo, fmt.Sprintf(format, args...), nil)
+	}
 }
 
 func (l *logger) Warning(v ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), yellow, warning, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if l.enabled(LevelWarning) {
+		l.print(LevelWarning, fmt.Sprintf("%s", v), nil)
+	}
 }
 
 func (l *logger) Warningf(format string, args ...interface{}) {
-	now := time.Now()
-	pc, fi, li, ok := runtime.Caller(value)
-	f := runtime.FuncForPC(pc).Name()
-	fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-		FormatNow(now), yellow, warning, reset, FileInfo(fi, li, ok), FuncInfo(f),
-		fmt.Sprintf(format, args...))
+	if l.enabled(LevelWarning) {
+		l.print(LevelWarning, fmt.Sprintf(format, args...), nil)
+	}
 }
 
 func (l *logger) Debug(v ...interface{}) {
-	if os.Getenv("MODE_DEBUG") == "true" {
-		now := time.Now()
-		pc, fi, li, ok := runtime.Caller(value)
-		f := runtime.FuncForPC(pc).Name()
-		fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-			FormatNow(now), green, debug, reset, FileInfo(fi, li, ok), FuncInfo(f), v)
+	if os.Getenv("MODE_DEBUG") == "true" && l.enabled(LevelDebug) {
+		l.print(LevelDebug, fmt.Sprintf("%s", v), nil)
 	}
 }
 
 func (l *logger) Debugf(format string, args ...interface{}) {
-	if os.Getenv("MODE_DEBUG") == "true" {
-		now := time.Now()
-		pc, fi, li, ok := runtime.Caller(value)
-		f := runtime.FuncForPC(pc).Name()
-		fmt.Printf("%s | %s %s %s | %20s | %20s | %s \n",
-			FormatNow(now), green, debug, reset, FileInfo(fi, li, ok), FuncInfo(f),
-			fmt.Sprintf(format, args...))
+	if os.Getenv("MODE_DEBUG") == "true" && l.enabled(LevelDebug) {
+		l.print(LevelDebug, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Log logs v at the given level, behaving as the method of the level, e.g. Fatal exits and Panic panics.
+// Unknown levels are logged at Info level.
+func (l *logger) Log(level Level, v ...interface{}) {
+	level = knownLevel(level)
+	if l.dynamicEnabled(level) {
+		var stack []byte
+		if level == LevelPanic {
+			stack = debug.Stack()
+		}
+		l.print(level, fmt.Sprintf("%s", v), stack)
+	}
+	l.terminate(level, fmt.Sprint(v...))
+}
+
+// Logf logs the formatted message at the given level, behaving as the method of the level,
+// e.g. Fatalf exits and Panicf panics. Unknown levels are logged at Info level.
+func (l *logger) Logf(level Level, format string, args ...interface{}) {
+	level = knownLevel(level)
+	if l.dynamicEnabled(level) {
+		var stack []byte
+		if level == LevelPanic {
+			stack = debug.Stack()
+		}
+		// The Error, Fatal and Panic methods format with fmt.Errorf, which supports the %w verb.
+		msg := fmt.Sprintf(format, args...)
+		if level >= LevelError {
+			msg = fmt.Errorf(format, args...).Error()
+		}
+		l.print(level, msg, stack)
+	}
+	l.terminate(level, fmt.Sprintf(format, args...))
+}
+
+// knownLevel returns level, or LevelInfo when it is not one of the defined levels.
+func knownLevel(level Level) Level {
+	if level < LevelDebug || level > LevelPanic {
+		return LevelInfo
+	}
+	return level
+}
+
+// dynamicEnabled reports whether a line at the given level is logged by Log and Logf,
+// which log Debug lines only when MODE_DEBUG is true, as Debug and Debugf do.
+func (l *logger) dynamicEnabled(level Level) bool {
+	if level == LevelDebug && os.Getenv("MODE_DEBUG") != "true" {
+		return false
+	}
+	return l.enabled(level)
+}
+
+// terminate exits for the Fatal level and panics with msg for the Panic level, as Log and Logf require.
+func (l *logger) terminate(level Level, msg string) {
+	switch level {
+	case LevelFatal:
+		l.osExitFunc(1)
+	case LevelPanic:
+		panic(msg)
 	}
 }
@@ -2,20 +2,33 @@ package logger
 
 import (
 	"bytes"
+	"encoding/json"
 	"log"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
-	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 const expectedMsg = "logger message"
 
+// exitMock mocks the function called by the logger to exit. The tests can't use the mocks package,
+// which imports the logger for MockLogger.
+type exitMock struct {
+	mock.Mock
+}
+
+func (m *exitMock) Exit(code int) {
+	m.Called(code)
+}
+
 type loggerScenery struct {
 	logger     *logger
 	IL         Logger
-	osExitMock *mocks.OSExitMock
+	osExitMock *exitMock
 }
 
 func givenLoggerScenery() *loggerScenery {
@@ -27,7 +40,7 @@ func givenLoggerScenery() *loggerScenery {
 }
 
 func givenLoggerSceneryFatalLogger() *loggerScenery {
-	osExitMock := &mocks.OSExitMock{}
+	osExitMock := &exitMock{}
 	osExitMock.On("Exit", 1).Once()
 	return &loggerScenery{
 		osExitMock: osExitMock,
@@ -233,3 +246,435 @@ func TestDebugLoggerModeDebugFFalse(t *testing.T) {
 	s.whenDebugFLoggerExecuted()
 	s.thenLoggerError(t, expectedMsg, output)
 }
+
+func TestLoggerCallerInfo(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output))
+
+	l.Info("info message")
+
+	assert.Contains(t, output.String(), "logger_test.go:")
+	assert.Contains(t, output.String(), "logger.TestLoggerCallerInfo()")
+	assert.Regexp(t, `^\d{4}/\d{2}/\d{2} - \d{2}:\d{2}:\d{2} \| .+ Info .+ \| +logger_test\.go:\d+ \| +logger\.TestLoggerCallerInfo\(\) \| \[info message\] \n$`,
+		output.String())
+}
+
+func TestLoggerWithoutCallerInfo(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo()).(*logger)
+
+	var callerLookups int
+	l.caller = func(skip int) (uintptr, string, int, bool) {
+		callerLookups++
+		return 0, "", 0, false
+	}
+
+	l.Info("info message")
+	l.Errorf("error %s", "message")
+
+	assert.Equal(t, 0, callerLookups)
+	assert.NotContains(t, output.String(), ".go:")
+	assert.NotContains(t, output.String(), "()")
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], " | "+blue+" Info "+reset+" | [info message] "))
+	assert.True(t, strings.HasSuffix(lines[1], " | "+red+" Error "+reset+" | error message "))
+}
+
+func TestLoggerWithFields(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	parent := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+
+	child := parent.WithFields(map[string]interface{}{"request_id": "abc-123"})
+	grandchild := child.WithFields(map[string]interface{}{"route": "/users/{id}"})
+
+	parent.Info("parent message")
+	child.Info("child message")
+	grandchild.Infof("grandchild %s", "message")
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], "| [parent message] "))
+	assert.True(t, strings.HasSuffix(lines[1], "| [child message] request_id=abc-123 "))
+	assert.True(t, strings.HasSuffix(lines[2], "| grandchild message request_id=abc-123 route=/users/{id} "))
+}
+
+func TestLoggerLogfmt(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt))
+
+	l.Infof("user %s logged in", "foo")
+
+	assert.Regexp(t, `^ts=\S+ level=info file=logger_test\.go:\d+ func=logger\.TestLoggerLogfmt\(\) msg="user foo logged in"\n$`,
+		output.String())
+}
+
+func TestLoggerLogfmtQuoting(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo()).
+		WithFields(map[string]interface{}{"query": "a=b", "request_id": "abc-123"})
+
+	l.Warningf("slow")
+	l.Errorf("key=value pairs")
+	l.Errorf(`say "hi"`)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.Regexp(t, `^ts=\S+ level=warning msg=slow query="a=b" request_id=abc-123$`, lines[0])
+	assert.Regexp(t, `^ts=\S+ level=error msg="key=value pairs" query="a=b" request_id=abc-123$`, lines[1])
+	assert.Regexp(t, `^ts=\S+ level=error msg="say \\"hi\\"" query="a=b" request_id=abc-123$`, lines[2])
+}
+
+func TestLoggerSetLevelEnabled(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+	child := l.WithFields(map[string]interface{}{"component": "payments"})
+
+	l.SetLevelEnabled(LevelWarning, false)
+
+	l.Info("info message")
+	l.Warning("warning message")
+	l.Warningf("warningf message")
+	l.Error("error message")
+	child.Warning("child warning message")
+
+	assert.Contains(t, output.String(), "[info message]")
+	assert.Contains(t, output.String(), "[error message]")
+	assert.NotContains(t, output.String(), "warning")
+
+	l.SetLevelEnabled(LevelWarning, true)
+	l.Warning("warning message")
+
+	assert.Contains(t, output.String(), "[warning message]")
+}
+
+func TestLoggerSetLevelEnabledFatalStillExits(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	osExitMock := &exitMock{}
+	osExitMock.On("Exit", 1).Once()
+	l := NewLogger(osExitMock.Exit, WithOutput(output))
+
+	l.SetLevelEnabled(LevelFatal, false)
+	l.Fatal("fatal message")
+
+	assert.Empty(t, output.String())
+	osExitMock.AssertExpectations(t)
+}
+
+func TestLoggerPanicStack(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+
+	assert.PanicsWithValue(t, "boom", func() { l.Panic("boom") })
+	assert.Contains(t, output.String(), "[boom]")
+	assert.Contains(t, output.String(), "goroutine ")
+	assert.Contains(t, output.String(), "logger.TestLoggerPanicStack")
+
+	output.Reset()
+	l.Info("info message")
+	assert.NotContains(t, output.String(), "goroutine ", "only Panic and Panicf include the stack")
+}
+
+func TestLoggerPanicfStackLogfmt(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo())
+
+	assert.PanicsWithValue(t, "boom 42", func() { l.Panicf("boom %d", 42) })
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 1, "the stack is written as a single quoted value")
+	assert.Regexp(t, `^ts=\S+ level=panic msg="boom 42" stack="goroutine \d+ \[running\]:\\n.*logger\.TestLoggerPanicfStackLogfmt`, lines[0])
+}
+
+func TestLoggerWithClock(t *testing.T) {
+	t.Parallel()
+	clock := func() time.Time {
+		return time.Date(2024, time.May, 12, 10, 30, 0, 0, time.UTC)
+	}
+
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo(), WithClock(clock))
+	l.Infof("server started")
+	assert.Equal(t, "2024/05/12 - 10:30:00 | "+blue+" Info "+reset+" | server started \n", output.String())
+
+	output.Reset()
+	l = NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo(), WithClock(clock))
+	l.Warningf("slow query")
+	assert.Equal(t, "ts=2024-05-12T10:30:00Z level=warning msg=\"slow query\"\n", output.String())
+}
+
+func TestLoggerWithTimestampPrecision(t *testing.T) {
+	t.Parallel()
+	clock := func() time.Time {
+		return time.Date(2024, time.May, 12, 10, 30, 0, 123456789, time.UTC)
+	}
+
+	tests := []struct {
+		precision TimestampPrecision
+		format    Format
+		expected  string
+	}{
+		{PrecisionSecond, FormatText, "2024/05/12 - 10:30:00 | "},
+		{PrecisionMillisecond, FormatText, "2024/05/12 - 10:30:00.123 | "},
+		{PrecisionMicrosecond, FormatText, "2024/05/12 - 10:30:00.123456 | "},
+		{PrecisionSecond, FormatLogfmt, "ts=2024-05-12T10:30:00Z "},
+		{PrecisionMillisecond, FormatLogfmt, "ts=2024-05-12T10:30:00.123Z "},
+		{PrecisionMicrosecond, FormatLogfmt, "ts=2024-05-12T10:30:00.123456Z "},
+	}
+
+	for _, tt := range tests {
+		output := new(bytes.Buffer)
+		l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(tt.format), WithoutCallerInfo(),
+			WithClock(clock), WithTimestampPrecision(tt.precision))
+		l.Info("message")
+		assert.True(t, strings.HasPrefix(output.String(), tt.expected), output.String())
+	}
+}
+
+func TestLoggerWithFileSegments(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := &logger{
+		out:      output,
+		segments: 2,
+		caller: func(int) (uintptr, string, int, bool) {
+			return 0, "/app/internal/foo/handler.go", 12, true
+		},
+		format: FormatLogfmt,
+	}
+
+	l.Info("message")
+	assert.Contains(t, output.String(), " file=foo/handler.go:12 ")
+}
+
+type fakeLevelCounter struct {
+	counts map[Level]int
+}
+
+func (c *fakeLevelCounter) Inc(level Level) {
+	c.counts[level]++
+}
+
+func TestLoggerWithLevelCounter(t *testing.T) {
+	t.Setenv("MODE_DEBUG", "false")
+	counter := &fakeLevelCounter{counts: make(map[Level]int)}
+	osExitMock := &exitMock{}
+	osExitMock.On("Exit", 1).Once()
+	l := NewLogger(osExitMock.Exit, WithOutput(new(bytes.Buffer)), WithLevelCounter(counter))
+	child := l.WithFields(map[string]interface{}{"component": "payments"})
+
+	l.Info("info message")
+	l.Infof("infof message")
+	child.Warning("warning message")
+	l.Errorf("error message")
+	l.Debug("debug message")
+	l.Fatal("fatal message")
+	assert.Panics(t, func() { l.Panic("panic message") })
+
+	l.SetLevelEnabled(LevelError, false)
+	l.Error("disabled error message")
+
+	assert.Equal(t, map[Level]int{LevelInfo: 2, LevelWarning: 1, LevelError: 1, LevelFatal: 1, LevelPanic: 1},
+		counter.counts)
+	osExitMock.AssertExpectations(t)
+}
+
+func TestLoggerWithSeparatorAndColumnWidths(t *testing.T) {
+	t.Parallel()
+	clock := func() time.Time { return time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC) }
+	caller := func(int) (uintptr, string, int, bool) { return 0, "/app/handler.go", 12, true }
+
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithClock(clock), WithSeparator(" ; "), WithColumnWidths(16, 0))
+	l.(*logger).caller = caller
+	l.Infof("message")
+
+	assert.Equal(t, "2024/05/12 - 10:00:00 ; "+blue+" Info "+reset+" ;    handler.go:12 ; () ; message \n", output.String())
+
+	output.Reset()
+	l = NewLogger(DefaultOSExit, WithOutput(output), WithClock(clock))
+	l.(*logger).caller = caller
+	l.Infof("message")
+
+	assert.Equal(t, "2024/05/12 - 10:00:00 | "+blue+" Info "+reset+" |        handler.go:12 |                   () | message \n",
+		output.String())
+}
+
+func TestLoggerJSON(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatJSON),
+		WithClock(func() time.Time { return time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC) })).
+		WithFields(map[string]interface{}{"request_id": "abc-123", "status": 500, "query": "a<b"})
+
+	l.Errorf(`say "hi"`)
+
+	assert.Regexp(t, `^\{"ts":"2024-05-12T10:00:00Z","level":"error","file":"logger_test\.go:\d+",`+
+		`"func":"logger\.TestLoggerJSON\(\)","msg":"say \\"hi\\"","query":"a<b","request_id":"abc-123","status":500\}\n$`,
+		output.String())
+}
+
+func TestLoggerLevelWithoutEscapeSequences(t *testing.T) {
+	t.Parallel()
+	for _, format := range []Format{FormatJSON, FormatLogfmt} {
+		output := new(bytes.Buffer)
+		l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(format), WithoutCallerInfo())
+
+		l.Info("info message")
+		l.Warning("warning message")
+		l.Error("error message")
+
+		assert.NotContains(t, output.String(), "\033[")
+		assert.NotContains(t, output.String(), reset)
+	}
+
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatJSON), WithoutCallerInfo())
+	l.Warning("warning message")
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &line))
+	assert.Equal(t, "warning", line["level"])
+}
+
+func TestLoggerNamed(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	parent := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+
+	parent.Named("payments").Infof("charge created")
+
+	assert.True(t, strings.HasSuffix(output.String(), "| [payments] charge created \n"))
+}
+
+func TestLoggerNamedNested(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	parent := NewLogger(DefaultOSExit, WithOutput(output), WithoutCallerInfo())
+	payments := parent.Named("payments")
+	refunds := payments.WithFields(map[string]interface{}{"request_id": "abc-123"}).Named("refunds")
+
+	parent.Infof("parent message")
+	payments.Infof("payments message")
+	refunds.Infof("refunds message")
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasSuffix(lines[0], "| parent message "))
+	assert.True(t, strings.HasSuffix(lines[1], "| [payments] payments message "))
+	assert.True(t, strings.HasSuffix(lines[2], "| [payments.refunds] refunds message request_id=abc-123 "))
+}
+
+func TestLoggerNamedStructured(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatJSON), WithoutCallerInfo()).
+		Named("payments").Named("refunds")
+
+	l.Infof("refund created")
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(output.Bytes(), &line))
+	assert.Equal(t, "payments.refunds", line["component"])
+	assert.Equal(t, "refund created", line["msg"])
+
+	output.Reset()
+	l = NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo()).Named("payments")
+	l.Infof("charge created")
+
+	assert.Regexp(t, `^ts=\S+ level=info component=payments msg="charge created"\n$`, output.String())
+}
+
+func TestLoggerLog(t *testing.T) {
+	t.Setenv("MODE_DEBUG", "true")
+
+	testCases := []struct {
+		level    Level
+		expected string
+	}{
+		{level: LevelDebug, expected: "debug"},
+		{level: LevelInfo, expected: "info"},
+		{level: LevelWarning, expected: "warning"},
+		{level: LevelError, expected: "error"},
+		{level: Level(42), expected: "info"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.level.String(), func(t *testing.T) {
+			output := new(bytes.Buffer)
+			l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt))
+
+			l.Log(tc.level, "dynamic message")
+			l.Logf(tc.level, "dynamic %s", "message")
+
+			lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			assert.Len(t, lines, 2)
+			assert.Regexp(t, `^ts=\S+ level=`+tc.expected+` file=logger_test\.go:\d+ func=logger\.TestLoggerLog\.func1\(\) `+
+				`msg="\[dynamic message\]"$`, lines[0])
+			assert.Regexp(t, `^ts=\S+ level=`+tc.expected+` .* msg="dynamic message"$`, lines[1])
+		})
+	}
+}
+
+func TestLoggerLogDebugModeDebugFalse(t *testing.T) {
+	t.Setenv("MODE_DEBUG", "false")
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output))
+
+	l.Log(LevelDebug, "debug message")
+	l.Logf(LevelDebug, "debug message")
+
+	assert.Empty(t, output.String())
+}
+
+func TestLoggerLogDisabledLevel(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output))
+	l.SetLevelEnabled(LevelWarning, false)
+
+	l.Log(LevelWarning, "warning message")
+	l.Logf(LevelWarning, "warning message")
+
+	assert.Empty(t, output.String())
+}
+
+func TestLoggerLogFatal(t *testing.T) {
+	t.Parallel()
+	osExitMock := &exitMock{}
+	osExitMock.On("Exit", 1).Twice()
+	output := new(bytes.Buffer)
+	l := NewLogger(osExitMock.Exit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo())
+
+	l.Log(LevelFatal, "fatal message")
+	l.Logf(LevelFatal, "fatal %s", "message")
+
+	osExitMock.AssertExpectations(t)
+	lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `^ts=\S+ level=fatal msg="\[fatal message\]"$`, lines[0])
+	assert.Regexp(t, `^ts=\S+ level=fatal msg="fatal message"$`, lines[1])
+}
+
+func TestLoggerLogPanic(t *testing.T) {
+	t.Parallel()
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt), WithoutCallerInfo())
+
+	assert.PanicsWithValue(t, "panic message", func() { l.Log(LevelPanic, "panic message") })
+	assert.Regexp(t, `^ts=\S+ level=panic msg="\[panic message\]" stack="goroutine \d+ \[running\]:\\n`, output.String())
+
+	output.Reset()
+	assert.PanicsWithValue(t, "panic message", func() { l.Logf(LevelPanic, "panic %s", "message") })
+	assert.Regexp(t, `^ts=\S+ level=panic msg="panic message" stack="goroutine \d+ \[running\]:\\n`, output.String())
+}
@@ -3,6 +3,8 @@ package logger
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -24,11 +26,31 @@ Parameters:
 @ ok: boolean indicating whether it was possible to retrieve the file information or not.
 */
 func FileInfo(file string, line int, ok bool) string {
+	return FileInfoN(file, line, ok, 1)
+}
+
+/*
+FileInfoN returns the concatenation of the last segments of the file path and the invoked line number,
+e.g. foo/handler.go:12 for 2 segments, which disambiguates files with the same name in different packages.
+Parameters:
+@ file: invoked file.
+@ line: line number associated with the file.
+@ ok: boolean indicating whether it was possible to retrieve the file information or not.
+@ segments: number of path segments to keep. Values lower than 1 keep only the file name.
+*/
+func FileInfoN(file string, line int, ok bool, segments int) string {
 	if !ok {
 		file = "<???>"
 		line = 1
 	} else {
-		slash := strings.LastIndex(file, "/")
+		if segments < 1 {
+			segments = 1
+		}
+
+		slash := len(file)
+		for i := 0; i < segments && slash >= 0; i++ {
+			slash = strings.LastIndex(file[:slash], "/")
+		}
 		if slash >= 0 {
 			file = file[slash+1:]
 		}
@@ -47,6 +69,42 @@ func FuncInfo(funcname string) string {
 	return fmt.Sprintf("%s()", fn)
 }
 
+/*
+FormatFields returns the fields as key=value pairs sorted by key. The values are formatted with FormatValue.
+Parameters:
+@ fields: fields to format.
+*/
+func FormatFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + FormatValue(fields[k])
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+/*
+FormatValue returns the value as a string suitable for a key=value pair. It is quoted when it is empty or contains spaces, quotes, equals signs or control characters.
+Parameters:
+@ v: value to format.
+*/
+func FormatValue(v interface{}) string {
+	value := fmt.Sprint(v)
+	if value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == 0x7f
+	}) >= 0 {
+		return strconv.Quote(value)
+	}
+
+	return value
+}
+
 // osExitFunc -> Interface that defines the behavior of the Exit function in the os package.
 type osExitFunc func(int)
 
@@ -5,18 +5,17 @@ import (
 	"testing"
 	"time"
 
-	mocks "github.com/JhonX2011/GOWebApplication/test/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
 type loggerUtilsScenery struct {
 	aResult    any
-	osExitMock *mocks.OSExitMock
+	osExitMock *exitMock
 	exitCode   int
 }
 
 func givenLoggerUtilsScenery() *loggerUtilsScenery {
-	osExitMock := &mocks.OSExitMock{}
+	osExitMock := &exitMock{}
 	osExitMock.On("Exit", 1).Once()
 	return &loggerUtilsScenery{
 		exitCode:   1,
@@ -69,6 +68,26 @@ func TestFileInfoFalse(t *testing.T) {
 	e.thenEqual(t, e.aResult, "<???>:1")
 }
 
+func TestFileInfoN(t *testing.T) {
+	tests := []struct {
+		file     string
+		segments int
+		expected string
+	}{
+		{"/app/internal/foo/handler.go", 1, "handler.go:12"},
+		{"/app/internal/foo/handler.go", 2, "foo/handler.go:12"},
+		{"/app/internal/foo/handler.go", 3, "internal/foo/handler.go:12"},
+		{"/app/internal/foo/handler.go", 0, "handler.go:12"},
+		{"foo/handler.go", 5, "foo/handler.go:12"},
+		{"handler.go", 2, "handler.go:12"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FileInfoN(tt.file, 12, true, tt.segments))
+	}
+	assert.Equal(t, "<???>:1", FileInfoN("/app/foo/handler.go", 12, false, 2))
+}
+
 func TestFuncInfo(t *testing.T) {
 	e := givenLoggerUtilsScenery()
 	e.whenFuncInfo("testFunction")
@@ -82,3 +101,35 @@ func TestDefaultOSExit(t *testing.T) {
 	e.thenAssertExpectations(t)
 	exitFunc = os.Exit
 }
+
+func TestFormatFields(t *testing.T) {
+	e := givenLoggerUtilsScenery()
+	e.aResult = FormatFields(map[string]interface{}{
+		"route":      "/users/{id}",
+		"request_id": "abc-123",
+		"status":     500,
+		"msg":        `say "hi" a=b`,
+		"empty":      "",
+	})
+	e.thenEqual(t, `empty="" msg="say \"hi\" a=b" request_id=abc-123 route=/users/{id} status=500`, e.aResult)
+}
+
+func TestFormatValue(t *testing.T) {
+	e := givenLoggerUtilsScenery()
+	testCases := map[interface{}]string{
+		"plain":         "plain",
+		"with space":    `"with space"`,
+		"a=b":           `"a=b"`,
+		"":              `""`,
+		"line\nbreak":   `"line\nbreak"`,
+		42:              "42",
+		"/users/{id}":   "/users/{id}",
+		`say "hi"`:      `"say \"hi\""`,
+		"tab\tseparate": `"tab\tseparate"`,
+	}
+
+	for value, expected := range testCases {
+		e.aResult = FormatValue(value)
+		e.thenEqual(t, expected, e.aResult)
+	}
+}
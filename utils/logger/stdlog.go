@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// RedirectStdLog sets the output of the standard library log package to l, so the lines written by
+// third-party libraries through log.Print and friends are formatted like the rest of the application logs.
+// Each line is logged at Info level, unless another level is given. The flags of the standard logger are
+// cleared, since l already adds the timestamp.
+func RedirectStdLog(l Logger, level ...Level) {
+	w := &levelWriter{logger: l, level: LevelInfo}
+	if len(level) > 0 {
+		w.level = level[0]
+	}
+
+	log.SetFlags(0)
+	log.SetPrefix("")
+	log.SetOutput(w)
+}
+
+// Writer returns an io.Writer that logs every line written to it at the given level, for libraries that
+// log through an io.Writer or a *log.Logger, e.g. http.Server{ErrorLog: log.New(l.Writer(LevelError), "", 0)}.
+// Since *log.Logger writes each message in a single call, each message becomes one log line.
+func (l *logger) Writer(level Level) io.Writer {
+	return &levelWriter{logger: l, level: level}
+}
+
+// levelWriter is an io.Writer that logs every line written to it at the given level.
+type levelWriter struct {
+	logger Logger
+	level  Level
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\r\n"), []byte("\n")) {
+		if len(line) > 0 {
+			w.log(string(bytes.TrimRight(line, "\r")))
+		}
+	}
+
+	return len(p), nil
+}
+
+// log logs line at the level of the writer. The lines logged by a *logger report the caller of the log
+// package, or of Write when it is called directly, since the caller lookup of the exported methods would
+// report levelWriter.log. Other Logger implementations are called through their level methods.
+func (w *levelWriter) log(line string) {
+	if l, ok := w.logger.(*logger); ok {
+		l.logWriterLine(w.level, line)
+		return
+	}
+
+	switch w.level {
+	case LevelDebug:
+		w.logger.Debugf("%s", line)
+	case LevelWarning:
+		w.logger.Warningf("%s", line)
+	case LevelError:
+		w.logger.Errorf("%s", line)
+	case LevelFatal:
+		w.logger.Fatalf("%s", line)
+	case LevelPanic:
+		w.logger.Panicf("%s", line)
+	default:
+		w.logger.Infof("%s", line)
+	}
+}
+
+// logWriterLine logs a line written to a levelWriter, behaving as Log. It must be called directly from
+// levelWriter.log. The caller columns are omitted when the caller of the writer can't be found.
+func (l *logger) logWriterLine(level Level, line string) {
+	level = knownLevel(level)
+	if l.dynamicEnabled(level) {
+		var stack []byte
+		if level == LevelPanic {
+			stack = debug.Stack()
+		}
+
+		var file, fn string
+		if !l.omitCaller {
+			var ok bool
+			if file, fn, ok = l.writerCaller(); !ok {
+				child := *l
+				child.omitCaller = true
+				l = &child
+			}
+		}
+		l.write(level, file, fn, line, stack)
+	}
+	l.terminate(level, line)
+}
+
+// _writerCallerSkip is the number of stack frames to skip from writerCaller to reach the caller of
+// levelWriter.Write: writerCaller, logWriterLine, levelWriter.log and levelWriter.Write.
+const _writerCallerSkip = 4
+
+// writerCaller returns the file and func of the caller of levelWriter.Write, skipping the frames of the
+// log package so that the lines of a *log.Logger report the caller of log.Printf and friends.
+func (l *logger) writerCaller() (file, fn string, ok bool) {
+	caller := l.callerFunc()
+	for skip := _writerCallerSkip; ; skip++ {
+		pc, fi, li, found := caller(skip)
+		if !found {
+			return "", "", false
+		}
+
+		name := runtime.FuncForPC(pc).Name()
+		if !strings.HasPrefix(name, "log.") {
+			return FileInfoN(fi, li, true, l.segments), FuncInfo(name), true
+		}
+	}
+}
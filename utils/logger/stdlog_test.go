@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func restoreStdLog(t *testing.T) {
+	out, flags, prefix := log.Writer(), log.Flags(), log.Prefix()
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFlags(flags)
+		log.SetPrefix(prefix)
+	})
+}
+
+func TestRedirectStdLog(t *testing.T) {
+	restoreStdLog(t)
+	output := new(bytes.Buffer)
+	RedirectStdLog(NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt)))
+
+	log.Println("message from a library")
+
+	assert.Contains(t, output.String(), ` level=info `)
+	assert.Contains(t, output.String(), ` msg="message from a library"`)
+	assert.Contains(t, output.String(), ` file=stdlog_test.go:`)
+	assert.Contains(t, output.String(), ` func=logger.TestRedirectStdLog()`)
+	assert.Equal(t, 1, bytes.Count(output.Bytes(), []byte("\n")))
+}
+
+func TestRedirectStdLog_WithLevel(t *testing.T) {
+	restoreStdLog(t)
+	output := new(bytes.Buffer)
+	RedirectStdLog(NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt)), LevelWarning)
+
+	log.Print("first line\nsecond line")
+
+	lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), ` level=warning `)
+	assert.Contains(t, string(lines[0]), ` msg="first line"`)
+	assert.Contains(t, string(lines[1]), ` msg="second line"`)
+}
+
+func TestLoggerWriter(t *testing.T) {
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt))
+
+	errorLog := log.New(l.Writer(LevelError), "", 0)
+	errorLog.Printf("http: TLS handshake error from %s: EOF", "10.0.0.1:53412")
+
+	assert.Contains(t, output.String(), ` level=error `)
+	assert.Contains(t, output.String(), ` msg="http: TLS handshake error from 10.0.0.1:53412: EOF"`)
+	assert.Contains(t, output.String(), ` func=logger.TestLoggerWriter()`)
+	assert.Equal(t, 1, bytes.Count(output.Bytes(), []byte("\n")))
+}
+
+func TestLoggerWriter_UnknownCaller(t *testing.T) {
+	output := new(bytes.Buffer)
+	l := NewLogger(DefaultOSExit, WithOutput(output), WithFormat(FormatLogfmt)).(*logger)
+	l.caller = func(int) (uintptr, string, int, bool) { return 0, "", 0, false }
+
+	_, err := l.Writer(LevelInfo).Write([]byte("message\n"))
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), ` msg=message`)
+	assert.NotContains(t, output.String(), ` file=`)
+	assert.NotContains(t, output.String(), ` func=`)
+}